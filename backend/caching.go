@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"binge-base/router"
+)
+
+// cachePolicies sets Cache-Control per GET route. TMDB data changes
+// slowly, so catalog responses are public; anything tied to a user is
+// private and revalidated every time, which costs a 304 when unchanged.
+// Routes not listed here, such as health checks and feeds, manage their
+// own caching. Settings and follows are also dated with Last-Modified; the
+// watchlist embeds live TMDB details, so only its ETag tells whether it
+// changed.
+var cachePolicies = map[string]string{
+	"/api/v1/genres":               "public, max-age=86400",
+	"/api/v1/genres/{id}/{type}":   "public, max-age=3600",
+	"/api/v1/trending":             "public, max-age=300",
+	"/api/v1/trending/movies":      "public, max-age=300",
+	"/api/v1/trending/tv":          "public, max-age=300",
+	"/api/v1/movie/{id}":           "public, max-age=3600",
+	"/api/v1/movie/{id}/providers": "public, max-age=3600",
+	"/api/v1/tv/{id}":              "public, max-age=3600",
+	// Adult results depend on the user's settings
+	"/api/v1/search":        "private, max-age=300",
+	"/api/v1/search/movies": "private, max-age=300",
+	"/api/v1/search/tv":     "private, max-age=300",
+	"/api/v1/search/people": "private, max-age=300",
+	"/api/v1/settings":      "private, no-cache",
+	"/api/v1/watchlist":     "private, no-cache",
+	"/api/v1/follows":       "private, no-cache",
+	// Followed titles change at most once per scan
+	"/api/v1/follows/releases": "private, max-age=300",
+	// The filmography, collection parts and episodes are marked with the
+	// user's watch state
+	"/api/v1/person/{id}":                               "private, no-cache",
+	"/api/v1/collection/{id}":                           "private, no-cache",
+	"/api/v1/tv/{id}/season/{season}":                   "private, no-cache",
+	"/api/v1/tv/{id}/season/{season}/episode/{episode}": "private, no-cache",
+}
+
+// Cache middleware. Registered on the router so policies are looked up by
+// route pattern. Errors are never cached, and a 200 whose ETag matches
+// If-None-Match, or without one whose Last-Modified is no later than
+// If-Modified-Since, is sent as a bodiless 304.
+func cacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, ok := cachePolicies[router.Pattern(r)]
+		if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", policy)
+		next.ServeHTTP(&conditionalWriter{
+			ResponseWriter:  w,
+			ifNoneMatch:     r.Header.Get("If-None-Match"),
+			ifModifiedSince: r.Header.Get("If-Modified-Since"),
+		}, r)
+	})
+}
+
+// conditionalWriter decides between 200, 304 and an uncacheable error once
+// the handler's status and ETag are known, without buffering the body
+type conditionalWriter struct {
+	http.ResponseWriter
+	ifNoneMatch     string
+	ifModifiedSince string
+	wroteHeader     bool
+	notModified     bool
+}
+
+func (cw *conditionalWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	h := cw.Header()
+	switch {
+	case status == http.StatusOK && cw.fresh(h):
+		cw.notModified = true
+		h.Del("Content-Length")
+		h.Del("Content-Type")
+		status = http.StatusNotModified
+	case status >= http.StatusBadRequest:
+		h.Set("Cache-Control", "no-store")
+		h.Del("ETag")
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+// fresh reports whether the client's copy is current. If-Modified-Since
+// only counts when If-None-Match is absent.
+func (cw *conditionalWriter) fresh(h http.Header) bool {
+	if cw.ifNoneMatch != "" {
+		return etagMatches(cw.ifNoneMatch, h.Get("ETag"))
+	}
+	lastModified, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	ims, err := http.ParseTime(cw.ifModifiedSince)
+	return err == nil && !lastModified.After(ims)
+}
+
+func (cw *conditionalWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.notModified {
+		return len(b), nil
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses working through the writer
+func (cw *conditionalWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok && !cw.notModified {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *conditionalWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// setLastModified dates a response for If-Modified-Since. Timestamps are
+// stored to the second, so a change later in the same second would carry
+// the same date; responses that recent are left to the ETag.
+func setLastModified(w http.ResponseWriter, modified time.Time) {
+	if modified.IsZero() || time.Since(modified) < time.Second {
+		return
+	}
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+}
+
+// etagMatches applies If-None-Match's weak comparison: W/ prefixes are
+// ignored and "*" matches any current representation
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"binge-base/router"
+)
+
+func TestCacheMiddlewareConditionalRequests(t *testing.T) {
+	rt := router.New()
+	rt.Use(cacheMiddleware)
+	rt.Get("/api/v1/settings", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		w.Header().Set("Last-Modified", "Sun, 18 Oct 2026 12:00:00 GMT")
+		w.Write([]byte(`{"success":true}`))
+	})
+
+	tests := []struct {
+		name                    string
+		ifNoneMatch, ifModSince string
+		status                  int
+	}{
+		{"unconditional", "", "", http.StatusOK},
+		{"etag matches", `"v1", W/"v2"`, "", http.StatusNotModified},
+		{"etag differs", `"v1"`, "", http.StatusOK},
+		{"not modified since", "", "Sun, 18 Oct 2026 12:00:00 GMT", http.StatusNotModified},
+		{"modified since", "", "Sun, 18 Oct 2026 11:59:59 GMT", http.StatusOK},
+		{"unparsable date", "", "yesterday", http.StatusOK},
+		{"etag wins over date", `"v1"`, "Sun, 18 Oct 2026 12:00:00 GMT", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/v1/settings", nil)
+		if tt.ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+		if tt.ifModSince != "" {
+			r.Header.Set("If-Modified-Since", tt.ifModSince)
+		}
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if tt.status == http.StatusNotModified && w.Body.Len() > 0 {
+			t.Errorf("%s: 304 with a body", tt.name)
+		}
+		if w.Header().Get("Cache-Control") != cachePolicies["/api/v1/settings"] {
+			t.Errorf("%s: Cache-Control %q", tt.name, w.Header().Get("Cache-Control"))
+		}
+	}
+}
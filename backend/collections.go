@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+
+	"binge-base/logging"
+	"binge-base/models"
+	"binge-base/router"
+	"binge-base/services"
+)
+
+// Collection handler. Parts are listed in release order and marked with
+// the user's watchlist.
+func (s *Server) collectionHandler(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid collection ID")
+		return
+	}
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+
+	collection, err := s.tmdbService.GetCollection(r.Context(), collectionID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Collection not found", "Failed to fetch collection")
+		return
+	}
+	if !s.annotateCollection(w, userID, collection) {
+		return
+	}
+	s.sendData(w, collection)
+}
+
+type addCollectionRequest struct {
+	UserID       string `json:"user_id"`
+	CollectionID int    `json:"collection_id"`
+}
+
+// Adds every part of a collection to the watchlist. Parts already on it
+// keep their watched state; the collection is returned with the new state.
+func (s *Server) addCollectionToWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var request addCollectionRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	if request.CollectionID <= 0 {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid collection ID")
+		return
+	}
+
+	collection, err := s.tmdbService.GetCollection(r.Context(), request.CollectionID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Collection not found", "Failed to fetch collection")
+		return
+	}
+	ids := make([]int, 0, len(collection.Parts))
+	for _, part := range collection.Parts {
+		ids = append(ids, part.ID)
+	}
+	added, err := s.db.AddAllToWatchlist(request.UserID, ids, models.MediaTypeMovie)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to add to watchlist")
+		return
+	}
+	for _, id := range added {
+		s.webhookService.Dispatch(request.UserID, services.EventWatchlistAdded, map[string]interface{}{
+			"content_id":   id,
+			"content_type": models.MediaTypeMovie,
+		})
+	}
+	if !s.annotateCollection(w, request.UserID, collection) {
+		return
+	}
+	s.sendData(w, collection)
+}
+
+// annotateCollection marks each part with the user's watchlist state
+func (s *Server) annotateCollection(w http.ResponseWriter, userID string, collection *models.Collection) bool {
+	states, err := s.watchStates(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get watchlist")
+		return false
+	}
+	for i := range collection.Parts {
+		part := &collection.Parts[i]
+		part.OnWatchlist, part.Watched = states.lookup(part.MediaType, part.ID)
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// minCompressBytes is the smallest body worth compressing; below it the
+// encoding overhead eats the savings
+const minCompressBytes = 1024
+
+// encoder is the streaming compressor interface shared by brotli and gzip
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoders lists the supported content codings in order of preference.
+// Brotli at level 5 produces smaller bodies than gzip's default level in
+// about the same time, which suits responses compressed on the fly.
+var encoders = []struct {
+	name string
+	pool *sync.Pool
+}{
+	{"br", &sync.Pool{New: func() interface{} {
+		return brotli.NewWriterLevel(io.Discard, 5)
+	}}},
+	{"gzip", &sync.Pool{New: func() interface{} {
+		zw, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return zw
+	}}},
+}
+
+// compressibleTypes are the media type prefixes worth compressing; images,
+// archives and precompressed files are passed through untouched
+var compressibleTypes = []string{
+	"text/", "application/json", "application/xml", "application/atom+xml",
+	"application/javascript", "image/svg+xml",
+}
+
+// Compression middleware. Negotiates a coding from Accept-Encoding and
+// compresses bodies of compressible types once they pass minCompressBytes.
+// Bodies are held back only until that threshold or the first Flush, so
+// streamed responses keep streaming.
+func compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if r.Method == http.MethodHead {
+			encoding = -1
+		}
+		// Uncompressed responses still pass through the writer so Vary is
+		// set once, after the handler's own headers
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the index in encoders of the client's
+// preferred coding, or -1 for identity. "*" stands for every coding the
+// header doesn't name; equal weights go to the coding we prefer.
+func negotiateEncoding(header string) int {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if coding != "" {
+			weights[coding] = q
+		}
+	}
+
+	best, bestQ := -1, 0.0
+	for i, enc := range encoders {
+		q, ok := weights[enc.name]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+	return best
+}
+
+// compressWriter decides per response whether to compress. Until it has
+// decided, the status and the first bytes of the body are held back.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding int
+	status   int
+	buf      []byte
+
+	wroteHeader bool // the handler called WriteHeader
+	decided     bool
+	enc         encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	if !cw.eligible() {
+		cw.passThrough()
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= minCompressBytes {
+		if err := cw.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends what has been written so far. A response flushed before
+// reaching the threshold is streaming, so it is compressed from here on.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if !cw.decided {
+			cw.startCompression()
+		}
+	}
+	if cw.enc != nil {
+		cw.enc.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// eligible reports whether the response may be compressed, judging by the
+// headers the handler has set
+func (cw *compressWriter) eligible() bool {
+	h := cw.Header()
+	if cw.encoding < 0 || cw.status != http.StatusOK || h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cw *compressWriter) passThrough() {
+	cw.decided = true
+	addVary(cw.Header(), "Accept-Encoding")
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressWriter) startCompression() error {
+	cw.decided = true
+	h := cw.Header()
+	addVary(h, "Accept-Encoding")
+	h.Set("Content-Encoding", encoders[cw.encoding].name)
+	h.Del("Content-Length")
+	h.Del("Accept-Ranges")
+	// The compressed bytes differ from what a strong ETag promises
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	cw.enc = encoders[cw.encoding].pool.Get().(encoder)
+	cw.enc.Reset(cw.ResponseWriter)
+	buf := cw.buf
+	cw.buf = nil
+	_, err := cw.enc.Write(buf)
+	return err
+}
+
+// close finishes the response once the handler has returned. Bodies that
+// never reached the threshold are sent as they are.
+func (cw *compressWriter) close() {
+	if cw.enc != nil {
+		cw.enc.Close()
+		cw.enc.Reset(io.Discard)
+		encoders[cw.encoding].pool.Put(cw.enc)
+		cw.enc = nil
+		return
+	}
+	if cw.decided {
+		return
+	}
+	if !cw.wroteHeader && len(cw.buf) == 0 {
+		// Nothing was written; let net/http send its implicit 200
+		addVary(cw.Header(), "Accept-Encoding")
+		return
+	}
+	cw.Header().Set("Content-Length", strconv.Itoa(len(cw.buf)))
+	cw.passThrough()
+	cw.ResponseWriter.Write(cw.buf)
+}
+
+// addVary adds a header name to Vary unless it is already listed
+func addVary(h http.Header, name string) {
+	for _, value := range h.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", name)
+}
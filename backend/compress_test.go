@@ -0,0 +1,73 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", "br"},
+		{"*;q=0.1, gzip;q=0.5", "gzip"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if i := negotiateEncoding(tt.header); i >= 0 {
+			got = encoders[i].name
+		}
+		if got != tt.want {
+			t.Errorf("Accept-Encoding %q: got %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompressMiddlewareRoundTrip(t *testing.T) {
+	body := strings.Repeat(`{"title":"Blade Runner"},`, 200)
+	handler := compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"abc"`)
+		io.WriteString(w, body)
+	}))
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	}
+	for coding, decode := range decoders {
+		r := httptest.NewRequest("GET", "/api/v1/trending", nil)
+		r.Header.Set("Accept-Encoding", coding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		h := w.Header()
+		if h.Get("Content-Encoding") != coding || h.Get("Vary") != "Accept-Encoding" || h.Get("ETag") != `W/"abc"` {
+			t.Errorf("%s: Content-Encoding %q Vary %q ETag %q", coding, h.Get("Content-Encoding"), h.Get("Vary"), h.Get("ETag"))
+		}
+		if w.Body.Len() >= len(body) {
+			t.Errorf("%s: %d bytes compressed from %d", coding, w.Body.Len(), len(body))
+		}
+		dr, err := decode(w.Body)
+		if err != nil {
+			t.Fatalf("%s: %v", coding, err)
+		}
+		if got, err := io.ReadAll(dr); err != nil || string(got) != body {
+			t.Errorf("%s: body did not round-trip: %v", coding, err)
+		}
+	}
+}
@@ -1,48 +1,127 @@
-package config
-
-import (
-	"os"
-	"strconv"
-)
-
-type Config struct {
-	Port           string
-	GinMode        string
-	TMDBAPIKey     string
-	OMDBAPIKey     string
-	DBPath         string
-	AllowedOrigins []string
-	TMDBRateLimit  int
-	OMDBRateLimit  int
-	CacheDuration  int
-}
-
-func Load() *Config {
-	return &Config{
-		Port:           getEnv("PORT", "8080"),
-		GinMode:        getEnv("GIN_MODE", "debug"),
-		TMDBAPIKey:     getEnv("TMDB_API_KEY", ""),
-		OMDBAPIKey:     getEnv("OMDB_API_KEY", ""),
-		DBPath:         getEnv("DB_PATH", "./database/bingebase.db"),
-		AllowedOrigins: []string{"http://localhost:5173", "http://localhost:3000"},
-		TMDBRateLimit:  getEnvAsInt("TMDB_RATE_LIMIT", 40),
-		OMDBRateLimit:  getEnvAsInt("OMDB_RATE_LIMIT", 1000),
-		CacheDuration:  getEnvAsInt("CACHE_DURATION", 3600),
-	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Config struct {
+	Port           string
+	GinMode        string
+	LogLevel       string
+	TMDBAPIKey     string
+	OMDBAPIKey     string
+	DBPath         string
+	AllowedOrigins []string
+	CORSAllowCreds bool
+	CORSMaxAge     int
+	TMDBRateLimit  int
+	OMDBRateLimit  int
+	CacheDuration  int
+	HealthProbeTTL int
+	ProbeOMDB      bool
+	ServeFrontend  bool
+	MetricsToken   string
+
+	RateLimitWindow     int
+	RateLimitSearch     int
+	RateLimitRead       int
+	RateLimitWrite      int
+	RateLimitTrustProxy bool
+	RateLimitProxyCIDRs []string
+	MaxBodyBytes        int64
+
+	ReadTimeout       int
+	ReadHeaderTimeout int
+	WriteTimeout      int
+	IdleTimeout       int
+	ShutdownTimeout   int
+	DrainDelay        int
+
+	WebhookMaxAttempts  int
+	WebhookTimeout      int
+	WebhookAllowPrivate bool
+	ReleaseNoticeDays   int
+}
+
+func Load() *Config {
+	return &Config{
+		Port:           getEnv("PORT", "8080"),
+		GinMode:        getEnv("GIN_MODE", "debug"),
+		LogLevel:       getEnv("LOG_LEVEL", "info"),
+		TMDBAPIKey:     getEnv("TMDB_API_KEY", ""),
+		OMDBAPIKey:     getEnv("OMDB_API_KEY", ""),
+		DBPath:         getEnv("DB_PATH", "./database/bingebase.db"),
+		AllowedOrigins: getEnvAsSlice("ALLOWED_ORIGINS", []string{"*"}),
+		CORSAllowCreds: getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAge:     getEnvAsInt("CORS_MAX_AGE", 600),
+		TMDBRateLimit:  getEnvAsInt("TMDB_RATE_LIMIT", 40),
+		OMDBRateLimit:  getEnvAsInt("OMDB_RATE_LIMIT", 1000),
+		CacheDuration:  getEnvAsInt("CACHE_DURATION", 3600),
+		HealthProbeTTL: getEnvAsInt("HEALTH_PROBE_TTL", 300),
+		ProbeOMDB:      getEnvAsBool("HEALTH_PROBE_OMDB", false),
+		ServeFrontend:  getEnvAsBool("SERVE_FRONTEND", true),
+		MetricsToken:   getEnv("METRICS_TOKEN", ""),
+
+		RateLimitWindow:     getEnvAsInt("RATE_LIMIT_WINDOW", 60),
+		RateLimitSearch:     getEnvAsInt("RATE_LIMIT_SEARCH", 30),
+		RateLimitRead:       getEnvAsInt("RATE_LIMIT_READ", 120),
+		RateLimitWrite:      getEnvAsInt("RATE_LIMIT_WRITE", 60),
+		RateLimitTrustProxy: getEnvAsBool("RATE_LIMIT_TRUST_PROXY", false),
+		RateLimitProxyCIDRs: getEnvAsSlice("RATE_LIMIT_TRUSTED_PROXIES", nil),
+		MaxBodyBytes:        int64(getEnvAsInt("MAX_BODY_BYTES", 16384)),
+
+		ReadTimeout:       getEnvAsInt("READ_TIMEOUT", 15),
+		ReadHeaderTimeout: getEnvAsInt("READ_HEADER_TIMEOUT", 5),
+		WriteTimeout:      getEnvAsInt("WRITE_TIMEOUT", 60),
+		IdleTimeout:       getEnvAsInt("IDLE_TIMEOUT", 120),
+		ShutdownTimeout:   getEnvAsInt("SHUTDOWN_TIMEOUT", 25),
+		DrainDelay:        getEnvAsInt("SHUTDOWN_DRAIN_DELAY", 5),
+
+		WebhookMaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookTimeout:      getEnvAsInt("WEBHOOK_TIMEOUT", 10),
+		WebhookAllowPrivate: getEnvAsBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+		ReleaseNoticeDays:   getEnvAsInt("RELEASE_NOTICE_DAYS", 7),
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice reads a comma-separated list, ignoring empty entries
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
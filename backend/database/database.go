@@ -1,240 +1,571 @@
-package database
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-	"os"
-	"path/filepath"
-
-	_ "github.com/mattn/go-sqlite3"
-)
-
-type Database struct {
-	DB *sql.DB
-}
-
-// NewDatabase creates a new database connection
-func NewDatabase(dbPath string) (*Database, error) {
-	// Ensure the database directory exists
-	dbDir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create database directory: %w", err)
-	}
-
-	// Open database connection
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Test the connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	database := &Database{DB: db}
-
-	// Initialize tables
-	if err := database.initTables(); err != nil {
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
-	}
-
-	log.Printf("✅ Database initialized successfully at %s", dbPath)
-	return database, nil
-}
-
-// Close closes the database connection
-func (d *Database) Close() error {
-	return d.DB.Close()
-}
-
-// initTables creates the necessary tables if they don't exist
-func (d *Database) initTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS movies (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			tmdb_id INTEGER UNIQUE NOT NULL,
-			title TEXT NOT NULL,
-			overview TEXT,
-			poster_path TEXT,
-			backdrop_path TEXT,
-			release_date TEXT,
-			vote_average REAL,
-			vote_count INTEGER,
-			popularity REAL,
-			runtime INTEGER,
-			status TEXT,
-			tagline TEXT,
-			budget INTEGER,
-			revenue INTEGER,
-			imdb_rating TEXT,
-			rotten_tomatoes_rating TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS tv_shows (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			tmdb_id INTEGER UNIQUE NOT NULL,
-			name TEXT NOT NULL,
-			overview TEXT,
-			poster_path TEXT,
-			backdrop_path TEXT,
-			first_air_date TEXT,
-			last_air_date TEXT,
-			vote_average REAL,
-			vote_count INTEGER,
-			popularity REAL,
-			number_of_seasons INTEGER,
-			number_of_episodes INTEGER,
-			status TEXT,
-			type TEXT,
-			imdb_rating TEXT,
-			rotten_tomatoes_rating TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS watchlist (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id TEXT NOT NULL,
-			content_id INTEGER NOT NULL,
-			content_type TEXT NOT NULL CHECK(content_type IN ('movie', 'tv')),
-			is_watched BOOLEAN DEFAULT FALSE,
-			added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			watched_at DATETIME,
-			UNIQUE(user_id, content_id, content_type)
-		)`,
-		`CREATE TABLE IF NOT EXISTS genres (
-			id INTEGER PRIMARY KEY,
-			name TEXT NOT NULL UNIQUE
-		)`,
-		`CREATE TABLE IF NOT EXISTS movie_genres (
-			movie_id INTEGER,
-			genre_id INTEGER,
-			PRIMARY KEY (movie_id, genre_id),
-			FOREIGN KEY (movie_id) REFERENCES movies(id),
-			FOREIGN KEY (genre_id) REFERENCES genres(id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS tv_genres (
-			tv_id INTEGER,
-			genre_id INTEGER,
-			PRIMARY KEY (tv_id, genre_id),
-			FOREIGN KEY (tv_id) REFERENCES tv_shows(id),
-			FOREIGN KEY (genre_id) REFERENCES genres(id)
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.DB.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// InsertMovie inserts a movie into the database
-func (d *Database) InsertMovie(movie interface{}) error {
-	// This will be implemented when we have the actual movie data structure
-	// For now, it's a placeholder
-	return nil
-}
-
-// InsertTVShow inserts a TV show into the database
-func (d *Database) InsertTVShow(tvShow interface{}) error {
-	// This will be implemented when we have the actual TV show data structure
-	// For now, it's a placeholder
-	return nil
-}
-
-// GetWatchlist retrieves watchlist items for a user
-func (d *Database) GetWatchlist(userID string) ([]interface{}, error) {
-	query := `
-		SELECT content_id, content_type, is_watched, added_at, watched_at
-		FROM watchlist
-		WHERE user_id = ?
-		ORDER BY added_at DESC
-	`
-
-	rows, err := d.DB.Query(query, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query watchlist: %w", err)
-	}
-	defer rows.Close()
-
-	var items []interface{}
-	for rows.Next() {
-		var contentID int
-		var contentType string
-		var isWatched bool
-		var addedAt string
-		var watchedAt *string
-		if err := rows.Scan(&contentID, &contentType, &isWatched, &addedAt, &watchedAt); err != nil {
-			continue
-		}
-		item := map[string]interface{}{
-			"content_id":   contentID,
-			"content_type": contentType,
-			"is_watched":   isWatched,
-			"added_at":     addedAt,
-			"watched_at":   watchedAt,
-		}
-		items = append(items, item)
-	}
-	return items, nil
-}
-
-// AddToWatchlist adds an item to the user's watchlist
-func (d *Database) AddToWatchlist(userID string, contentID int, contentType string) error {
-	query := `
-		INSERT OR REPLACE INTO watchlist (user_id, content_id, content_type, is_watched, added_at)
-		VALUES (?, ?, ?, FALSE, CURRENT_TIMESTAMP)
-	`
-
-	_, err := d.DB.Exec(query, userID, contentID, contentType)
-	if err != nil {
-		return fmt.Errorf("failed to add to watchlist: %w", err)
-	}
-
-	return nil
-}
-
-// RemoveFromWatchlist removes an item from the user's watchlist
-func (d *Database) RemoveFromWatchlist(userID string, contentID int, contentType string) error {
-	query := `
-		DELETE FROM watchlist
-		WHERE user_id = ? AND content_id = ? AND content_type = ?
-	`
-
-	_, err := d.DB.Exec(query, userID, contentID, contentType)
-	if err != nil {
-		return fmt.Errorf("failed to remove from watchlist: %w", err)
-	}
-
-	return nil
-}
-
-// MarkAsWatched marks an item as watched in the user's watchlist
-func (d *Database) MarkAsWatched(userID string, contentID int, contentType string, watched bool) error {
-	var query string
-	if watched {
-		query = `
-			UPDATE watchlist
-			SET is_watched = TRUE, watched_at = CURRENT_TIMESTAMP
-			WHERE user_id = ? AND content_id = ? AND content_type = ?
-		`
-	} else {
-		query = `
-			UPDATE watchlist
-			SET is_watched = FALSE, watched_at = NULL
-			WHERE user_id = ? AND content_id = ? AND content_type = ?
-		`
-	}
-
-	_, err := d.DB.Exec(query, userID, contentID, contentType)
-	if err != nil {
-		return fmt.Errorf("failed to mark as watched: %w", err)
-	}
-
-	return nil
-}
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"binge-base/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type Database struct {
+	DB *sql.DB
+}
+
+// NewDatabase creates a new database connection
+func NewDatabase(dbPath string) (*Database, error) {
+	// Ensure the database directory exists
+	dbDir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dbDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	// Open database connection
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	database := &Database{DB: db}
+
+	// Create or upgrade the schema
+	if err := database.migrate(); err != nil {
+		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	}
+
+	slog.Info("database initialized", "path", dbPath)
+	return database, nil
+}
+
+// Close closes the database connection
+func (d *Database) Close() error {
+	return d.DB.Close()
+}
+
+// migrations holds the schema changes in order. A database's position is
+// tracked in PRAGMA user_version; append new migrations, never edit old ones.
+var migrations = [][]string{
+	// 1: initial schema
+	{
+		`CREATE TABLE IF NOT EXISTS movies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tmdb_id INTEGER UNIQUE NOT NULL,
+			title TEXT NOT NULL,
+			overview TEXT,
+			poster_path TEXT,
+			backdrop_path TEXT,
+			release_date TEXT,
+			vote_average REAL,
+			vote_count INTEGER,
+			popularity REAL,
+			runtime INTEGER,
+			status TEXT,
+			tagline TEXT,
+			budget INTEGER,
+			revenue INTEGER,
+			imdb_rating TEXT,
+			rotten_tomatoes_rating TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS tv_shows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tmdb_id INTEGER UNIQUE NOT NULL,
+			name TEXT NOT NULL,
+			overview TEXT,
+			poster_path TEXT,
+			backdrop_path TEXT,
+			first_air_date TEXT,
+			last_air_date TEXT,
+			vote_average REAL,
+			vote_count INTEGER,
+			popularity REAL,
+			number_of_seasons INTEGER,
+			number_of_episodes INTEGER,
+			status TEXT,
+			type TEXT,
+			imdb_rating TEXT,
+			rotten_tomatoes_rating TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS watchlist (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			content_id INTEGER NOT NULL,
+			content_type TEXT NOT NULL CHECK(content_type IN ('movie', 'tv')),
+			is_watched BOOLEAN DEFAULT FALSE,
+			added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			watched_at DATETIME,
+			UNIQUE(user_id, content_id, content_type)
+		)`,
+		`CREATE TABLE IF NOT EXISTS genres (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE
+		)`,
+		`CREATE TABLE IF NOT EXISTS movie_genres (
+			movie_id INTEGER,
+			genre_id INTEGER,
+			PRIMARY KEY (movie_id, genre_id),
+			FOREIGN KEY (movie_id) REFERENCES movies(id),
+			FOREIGN KEY (genre_id) REFERENCES genres(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS tv_genres (
+			tv_id INTEGER,
+			genre_id INTEGER,
+			PRIMARY KEY (tv_id, genre_id),
+			FOREIGN KEY (tv_id) REFERENCES tv_shows(id),
+			FOREIGN KEY (genre_id) REFERENCES genres(id)
+		)`,
+	},
+	// 2: webhooks and their delivery log
+	{
+		`CREATE TABLE IF NOT EXISTS webhooks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			events TEXT NOT NULL,
+			active BOOLEAN DEFAULT TRUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			webhook_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			event TEXT NOT NULL,
+			attempt INTEGER NOT NULL,
+			status_code INTEGER,
+			success BOOLEAN DEFAULT FALSE,
+			error TEXT,
+			duration_ms INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS release_notifications (
+			user_id TEXT NOT NULL,
+			content_id INTEGER NOT NULL,
+			content_type TEXT NOT NULL,
+			release_date TEXT NOT NULL,
+			notified_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, content_id, content_type, release_date)
+		)`,
+	},
+	// 3: per-user settings
+	{
+		`CREATE TABLE IF NOT EXISTS user_settings (
+			user_id TEXT PRIMARY KEY,
+			include_adult BOOLEAN NOT NULL DEFAULT FALSE,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+	},
+	// 4: movie and TV genres share one catalog, flagged by media type
+	{
+		`ALTER TABLE genres ADD COLUMN movie BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE genres ADD COLUMN tv BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE genres ADD COLUMN updated_at DATETIME`,
+	},
+	// 5: followed people and companies, and the titles found for them
+	{
+		`CREATE TABLE IF NOT EXISTS follow_targets (
+			target_type TEXT NOT NULL CHECK(target_type IN ('person', 'company')),
+			target_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			checked_at DATETIME,
+			PRIMARY KEY (target_type, target_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS follows (
+			user_id TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, target_type, target_id),
+			FOREIGN KEY (target_type, target_id) REFERENCES follow_targets(target_type, target_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS followed_titles (
+			target_type TEXT NOT NULL,
+			target_id INTEGER NOT NULL,
+			content_id INTEGER NOT NULL,
+			content_type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			release_date TEXT,
+			poster_path TEXT,
+			role TEXT,
+			baseline BOOLEAN NOT NULL DEFAULT FALSE,
+			discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (target_type, target_id, content_id, content_type),
+			FOREIGN KEY (target_type, target_id) REFERENCES follow_targets(target_type, target_id)
+		)`,
+	},
+	// 6: per-episode watch tracking
+	{
+		`CREATE TABLE IF NOT EXISTS watched_episodes (
+			user_id TEXT NOT NULL,
+			show_id INTEGER NOT NULL,
+			season_number INTEGER NOT NULL,
+			episode_number INTEGER NOT NULL,
+			watched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, show_id, season_number, episode_number)
+		)`,
+	},
+	// 7: genre names are no longer unique, so a TMDB rename can take the
+	// name of a genre that dropped out of the catalog. SQLite can't drop a
+	// constraint, so the table is rebuilt.
+	{
+		`CREATE TABLE genres_new (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			movie BOOLEAN NOT NULL DEFAULT FALSE,
+			tv BOOLEAN NOT NULL DEFAULT FALSE,
+			updated_at DATETIME
+		)`,
+		`INSERT INTO genres_new (id, name, movie, tv, updated_at)
+			SELECT id, name, movie, tv, updated_at FROM genres`,
+		`DROP TABLE genres`,
+		`ALTER TABLE genres_new RENAME TO genres`,
+	},
+	// 8: what dates a user's follows list besides its rows: removed
+	// follows and renamed targets
+	{
+		`CREATE TABLE IF NOT EXISTS follow_changes (
+			user_id TEXT PRIMARY KEY,
+			changed_at DATETIME NOT NULL
+		)`,
+		`ALTER TABLE follow_targets ADD COLUMN renamed_at DATETIME`,
+	},
+	// 9: when a user's watchlist last changed, which its rows can't tell
+	// once an item is removed or unwatched
+	{
+		`CREATE TABLE IF NOT EXISTS watchlist_changes (
+			user_id TEXT PRIMARY KEY,
+			changed_at DATETIME NOT NULL
+		)`,
+	},
+}
+
+// migrate applies any migrations newer than the database's schema version
+func (d *Database) migrate() error {
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		tx, err := d.DB.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		for _, query := range migrations[i] {
+			if _, err := tx.Exec(query); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+			}
+		}
+		// PRAGMA doesn't accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+		slog.Info("applied database migration", "version", i+1)
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the number of migrations applied to the database
+func (d *Database) SchemaVersion() (int, error) {
+	var version int
+	if err := d.queryRow("SchemaVersion", "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion returns the schema version this build migrates to
+func LatestSchemaVersion() int {
+	return len(migrations)
+}
+
+// Ping verifies the database is reachable and answering queries
+func (d *Database) Ping(ctx context.Context) error {
+	start := time.Now()
+	var one int
+	err := d.DB.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+	observeQuery("Ping", start, err)
+	return err
+}
+
+// InsertMovie inserts a movie into the database
+func (d *Database) InsertMovie(movie interface{}) error {
+	// This will be implemented when we have the actual movie data structure
+	// For now, it's a placeholder
+	return nil
+}
+
+// InsertTVShow inserts a TV show into the database
+func (d *Database) InsertTVShow(tvShow interface{}) error {
+	// This will be implemented when we have the actual TV show data structure
+	// For now, it's a placeholder
+	return nil
+}
+
+// GetWatchlist retrieves watchlist items for a user
+func (d *Database) GetWatchlist(userID string) ([]interface{}, error) {
+	query := `
+		SELECT content_id, content_type, is_watched, added_at, watched_at
+		FROM watchlist
+		WHERE user_id = ?
+		ORDER BY added_at DESC
+	`
+
+	rows, err := d.query("GetWatchlist", query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	var items []interface{}
+	for rows.Next() {
+		var contentID int
+		var contentType string
+		var isWatched bool
+		var addedAt string
+		var watchedAt *string
+		if err := rows.Scan(&contentID, &contentType, &isWatched, &addedAt, &watchedAt); err != nil {
+			continue
+		}
+		item := map[string]interface{}{
+			"content_id":   contentID,
+			"content_type": contentType,
+			"is_watched":   isWatched,
+			"added_at":     addedAt,
+			"watched_at":   watchedAt,
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetWatchlistItems retrieves every watchlist item of a user, newest first
+func (d *Database) GetWatchlistItems(userID string) ([]models.WatchlistItem, error) {
+	query := `
+		SELECT id, user_id, content_id, content_type, is_watched, added_at, watched_at
+		FROM watchlist
+		WHERE user_id = ?
+		ORDER BY added_at DESC
+	`
+
+	rows, err := d.query("GetWatchlistItems", query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.WatchlistItem
+	for rows.Next() {
+		var item models.WatchlistItem
+		var watchedAt sql.NullTime
+		if err := rows.Scan(&item.ID, &item.UserID, &item.ContentID, &item.ContentType, &item.IsWatched, &item.AddedAt, &watchedAt); err != nil {
+			continue
+		}
+		if watchedAt.Valid {
+			item.WatchedAt = &watchedAt.Time
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// AddToWatchlist adds an item to the user's watchlist
+func (d *Database) AddToWatchlist(userID string, contentID int, contentType string) error {
+	query := `
+		INSERT OR REPLACE INTO watchlist (user_id, content_id, content_type, is_watched, added_at)
+		VALUES (?, ?, ?, FALSE, CURRENT_TIMESTAMP)
+	`
+
+	if err := d.changeWatchlist("AddToWatchlist", userID, query, userID, contentID, contentType); err != nil {
+		return fmt.Errorf("failed to add to watchlist: %w", err)
+	}
+
+	return nil
+}
+
+// changeWatchlist runs a statement on a user's watchlist and, if it
+// changed a row, records when in the same transaction
+func (d *Database) changeWatchlist(op, userID, query string, args ...interface{}) (err error) {
+	start := time.Now()
+	defer func() { observeQuery(op, start, err) }()
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		if err = recordWatchlistChange(tx, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func recordWatchlistChange(tx *sql.Tx, userID string) error {
+	_, err := tx.Exec(`
+		INSERT INTO watchlist_changes (user_id, changed_at)
+		VALUES (?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET changed_at = excluded.changed_at
+	`, userID)
+	return err
+}
+
+// WatchlistModifiedAt returns when a user's watchlist last changed: an
+// item added, removed, watched or unwatched. It is zero for a user who
+// never had a watchlist.
+func (d *Database) WatchlistModifiedAt(userID string) (time.Time, error) {
+	// Rows added before changes were recorded still date the list
+	query := `
+		SELECT MAX(changed) FROM (
+			SELECT changed_at AS changed FROM watchlist_changes WHERE user_id = ?
+			UNION ALL
+			SELECT MAX(added_at, COALESCE(watched_at, '')) FROM watchlist WHERE user_id = ?
+		)
+	`
+
+	var changed sql.NullString
+	if err := d.queryRow("WatchlistModifiedAt", query, userID, userID).Scan(&changed); err != nil {
+		return time.Time{}, fmt.Errorf("failed to date watchlist: %w", err)
+	}
+	if !changed.Valid {
+		return time.Time{}, nil
+	}
+	modified, err := time.Parse(sqliteTimestamp, changed.String)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse watchlist date: %w", err)
+	}
+	return modified, nil
+}
+
+// AddAllToWatchlist adds several titles of one type in a single
+// transaction and returns the IDs that were not already on the watchlist.
+// Titles already there keep their watched state.
+func (d *Database) AddAllToWatchlist(userID string, contentIDs []int, contentType string) (added []int, err error) {
+	start := time.Now()
+	defer func() { observeQuery("AddAllToWatchlist", start, err) }()
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin watchlist update: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, contentID := range contentIDs {
+		result, err := tx.Exec(`
+			INSERT OR IGNORE INTO watchlist (user_id, content_id, content_type, is_watched, added_at)
+			VALUES (?, ?, ?, FALSE, CURRENT_TIMESTAMP)
+		`, userID, contentID, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add to watchlist: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			added = append(added, contentID)
+		}
+	}
+	if len(added) > 0 {
+		if err = recordWatchlistChange(tx, userID); err != nil {
+			return nil, fmt.Errorf("failed to record watchlist change: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit watchlist update: %w", err)
+	}
+	return added, nil
+}
+
+// RemoveFromWatchlist removes an item from the user's watchlist
+func (d *Database) RemoveFromWatchlist(userID string, contentID int, contentType string) error {
+	query := `
+		DELETE FROM watchlist
+		WHERE user_id = ? AND content_id = ? AND content_type = ?
+	`
+
+	if err := d.changeWatchlist("RemoveFromWatchlist", userID, query, userID, contentID, contentType); err != nil {
+		return fmt.Errorf("failed to remove from watchlist: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAsWatched marks an item as watched in the user's watchlist
+func (d *Database) MarkAsWatched(userID string, contentID int, contentType string, watched bool) error {
+	var query string
+	if watched {
+		query = `
+			UPDATE watchlist
+			SET is_watched = TRUE, watched_at = CURRENT_TIMESTAMP
+			WHERE user_id = ? AND content_id = ? AND content_type = ?
+		`
+	} else {
+		query = `
+			UPDATE watchlist
+			SET is_watched = FALSE, watched_at = NULL
+			WHERE user_id = ? AND content_id = ? AND content_type = ?
+		`
+	}
+
+	if err := d.changeWatchlist("MarkAsWatched", userID, query, userID, contentID, contentType); err != nil {
+		return fmt.Errorf("failed to mark as watched: %w", err)
+	}
+
+	return nil
+}
+
+// GetWatchlistActivity retrieves a user's watchlist items ordered by their most
+// recent activity (added or watched)
+func (d *Database) GetWatchlistActivity(userID string, limit int) ([]models.WatchlistItem, error) {
+	query := `
+		SELECT id, user_id, content_id, content_type, is_watched, added_at, watched_at
+		FROM watchlist
+		WHERE user_id = ?
+		ORDER BY COALESCE(watched_at, added_at) DESC
+		LIMIT ?
+	`
+
+	rows, err := d.query("GetWatchlistActivity", query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist activity: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.WatchlistItem
+	for rows.Next() {
+		var item models.WatchlistItem
+		var watchedAt sql.NullTime
+		if err := rows.Scan(&item.ID, &item.UserID, &item.ContentID, &item.ContentType, &item.IsWatched, &item.AddedAt, &watchedAt); err != nil {
+			continue
+		}
+		if watchedAt.Valid {
+			item.WatchedAt = &watchedAt.Time
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
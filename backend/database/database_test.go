@@ -0,0 +1,56 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchlistModifiedAt(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	modified, err := db.WatchlistModifiedAt("alice")
+	if err != nil || !modified.IsZero() {
+		t.Fatalf("empty watchlist: %v %v, want zero", modified, err)
+	}
+
+	if err := db.AddToWatchlist("alice", 550, "movie"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.MarkAsWatched("alice", 550, "movie", true); err != nil {
+		t.Fatal(err)
+	}
+	// Back-date everything so later changes are visible at second precision
+	for _, stmt := range []string{
+		`UPDATE watchlist SET added_at = '2026-01-01 00:00:00', watched_at = '2026-01-02 00:00:00'`,
+		`UPDATE watchlist_changes SET changed_at = '2026-01-02 00:00:00'`,
+	} {
+		if _, err := db.DB.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if modified, _ := db.WatchlistModifiedAt("alice"); !modified.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("after watching: %v", modified)
+	}
+
+	if err := db.MarkAsWatched("alice", 550, "movie", false); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := db.WatchlistModifiedAt("alice"); time.Since(modified) > time.Minute {
+		t.Errorf("unwatch not dated: %v", modified)
+	}
+
+	if _, err := db.DB.Exec(`UPDATE watchlist_changes SET changed_at = '2026-01-02 00:00:00'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RemoveFromWatchlist("alice", 550, "movie"); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := db.WatchlistModifiedAt("alice"); time.Since(modified) > time.Minute {
+		t.Errorf("removal not dated: %v", modified)
+	}
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"fmt"
+
+	"binge-base/models"
+)
+
+// SetEpisodeWatched records or clears that a user watched an episode.
+// Marking an episode watched again keeps the original date.
+func (d *Database) SetEpisodeWatched(userID string, showID, seasonNumber, episodeNumber int, watched bool) error {
+	query := `
+		INSERT OR IGNORE INTO watched_episodes (user_id, show_id, season_number, episode_number, watched_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+	if !watched {
+		query = `
+			DELETE FROM watched_episodes
+			WHERE user_id = ? AND show_id = ? AND season_number = ? AND episode_number = ?
+		`
+	}
+
+	if _, err := d.exec("SetEpisodeWatched", query, userID, showID, seasonNumber, episodeNumber); err != nil {
+		return fmt.Errorf("failed to update watched episode: %w", err)
+	}
+	return nil
+}
+
+// GetWatchedEpisodes retrieves the episodes of a show a user has watched
+func (d *Database) GetWatchedEpisodes(userID string, showID int) ([]models.WatchedEpisode, error) {
+	query := `
+		SELECT user_id, show_id, season_number, episode_number, watched_at
+		FROM watched_episodes
+		WHERE user_id = ? AND show_id = ?
+		ORDER BY season_number, episode_number
+	`
+
+	rows, err := d.query("GetWatchedEpisodes", query, userID, showID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var episodes []models.WatchedEpisode
+	for rows.Next() {
+		var episode models.WatchedEpisode
+		if err := rows.Scan(&episode.UserID, &episode.ShowID, &episode.SeasonNumber, &episode.EpisodeNumber, &episode.WatchedAt); err != nil {
+			continue
+		}
+		episodes = append(episodes, episode)
+	}
+	return episodes, nil
+}
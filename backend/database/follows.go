@@ -0,0 +1,304 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"binge-base/models"
+)
+
+// sqliteTimestamp is the layout of CURRENT_TIMESTAMP, which computed
+// columns return as text rather than a parsed time
+const sqliteTimestamp = "2006-01-02 15:04:05"
+
+// AddFollow makes a user follow a person or company. Following again keeps
+// the original date; the name is refreshed.
+func (d *Database) AddFollow(userID string, target models.FollowTarget) (*models.Follow, error) {
+	_, err := d.exec("AddFollow", `
+		INSERT INTO follow_targets (target_type, target_id, name)
+		VALUES (?, ?, ?)
+		ON CONFLICT(target_type, target_id) DO UPDATE SET name = excluded.name, renamed_at = CURRENT_TIMESTAMP
+		WHERE name != excluded.name
+	`, target.TargetType, target.TargetID, target.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save follow target: %w", err)
+	}
+
+	_, err = d.exec("AddFollow", `
+		INSERT OR IGNORE INTO follows (user_id, target_type, target_id, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+	`, userID, target.TargetType, target.TargetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add follow: %w", err)
+	}
+
+	return d.GetFollow(userID, target.TargetType, target.TargetID)
+}
+
+// GetFollow retrieves one of a user's follows
+func (d *Database) GetFollow(userID, targetType string, targetID int) (*models.Follow, error) {
+	query := `
+		SELECT f.user_id, f.target_type, f.target_id, t.name, t.checked_at, f.created_at
+		FROM follows f
+		JOIN follow_targets t ON t.target_type = f.target_type AND t.target_id = f.target_id
+		WHERE f.user_id = ? AND f.target_type = ? AND f.target_id = ?
+	`
+
+	follow, err := scanFollow(d.queryRow("GetFollow", query, userID, targetType, targetID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow: %w", err)
+	}
+	return follow, nil
+}
+
+// GetFollows retrieves everyone and everything a user follows, newest first
+func (d *Database) GetFollows(userID string) ([]models.Follow, error) {
+	query := `
+		SELECT f.user_id, f.target_type, f.target_id, t.name, t.checked_at, f.created_at
+		FROM follows f
+		JOIN follow_targets t ON t.target_type = f.target_type AND t.target_id = f.target_id
+		WHERE f.user_id = ?
+		ORDER BY f.created_at DESC
+	`
+
+	rows, err := d.query("GetFollows", query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follows: %w", err)
+	}
+	defer rows.Close()
+
+	follows := []models.Follow{}
+	for rows.Next() {
+		follow, err := scanFollow(rows)
+		if err != nil {
+			continue
+		}
+		follows = append(follows, *follow)
+	}
+	return follows, nil
+}
+
+// FollowsModifiedAt returns when a user's follows list last changed: a
+// follow added or removed, or a followed target renamed or scanned. It is
+// zero for a user who never followed anything.
+func (d *Database) FollowsModifiedAt(userID string) (time.Time, error) {
+	query := `
+		SELECT MAX(changed) FROM (
+			SELECT changed_at AS changed FROM follow_changes WHERE user_id = ?
+			UNION ALL
+			SELECT MAX(f.created_at, COALESCE(t.checked_at, ''), COALESCE(t.renamed_at, ''))
+			FROM follows f
+			JOIN follow_targets t ON t.target_type = f.target_type AND t.target_id = f.target_id
+			WHERE f.user_id = ?
+		)
+	`
+
+	var changed sql.NullString
+	if err := d.queryRow("FollowsModifiedAt", query, userID, userID).Scan(&changed); err != nil {
+		return time.Time{}, fmt.Errorf("failed to date follows: %w", err)
+	}
+	if !changed.Valid {
+		return time.Time{}, nil
+	}
+	modified, err := time.Parse(sqliteTimestamp, changed.String)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse follows date: %w", err)
+	}
+	return modified, nil
+}
+
+// DeleteFollow stops a user following a person or company. Targets nobody
+// follows any more are forgotten along with their titles, so following
+// them again starts from a fresh baseline. It all happens in one
+// transaction so a scan of the target can't land halfway through.
+func (d *Database) DeleteFollow(userID, targetType string, targetID int) (err error) {
+	start := time.Now()
+	defer func() { observeQuery("DeleteFollow", start, err) }()
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin follow removal: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM follows WHERE user_id = ? AND target_type = ? AND target_id = ?`,
+		userID, targetType, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete follow: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err = tx.Exec(`
+		INSERT INTO follow_changes (user_id, changed_at)
+		VALUES (?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET changed_at = excluded.changed_at
+	`, userID); err != nil {
+		return fmt.Errorf("failed to record follow change: %w", err)
+	}
+
+	var followers int
+	if err = tx.QueryRow(`SELECT COUNT(*) FROM follows WHERE target_type = ? AND target_id = ?`,
+		targetType, targetID).Scan(&followers); err != nil {
+		return fmt.Errorf("failed to count followers: %w", err)
+	}
+	if followers == 0 {
+		if _, err = tx.Exec(`DELETE FROM followed_titles WHERE target_type = ? AND target_id = ?`, targetType, targetID); err != nil {
+			return fmt.Errorf("failed to delete followed titles: %w", err)
+		}
+		if _, err = tx.Exec(`DELETE FROM follow_targets WHERE target_type = ? AND target_id = ?`, targetType, targetID); err != nil {
+			return fmt.Errorf("failed to delete follow target: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit follow removal: %w", err)
+	}
+	return nil
+}
+
+// GetFollowTargets lists every person and company with at least one follower
+func (d *Database) GetFollowTargets() ([]models.FollowTarget, error) {
+	query := `
+		SELECT t.target_type, t.target_id, t.name, t.checked_at
+		FROM follow_targets t
+		WHERE EXISTS (SELECT 1 FROM follows f WHERE f.target_type = t.target_type AND f.target_id = t.target_id)
+		ORDER BY t.checked_at IS NOT NULL, t.checked_at
+	`
+
+	rows, err := d.query("GetFollowTargets", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follow targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []models.FollowTarget
+	for rows.Next() {
+		var target models.FollowTarget
+		var checkedAt sql.NullTime
+		if err := rows.Scan(&target.TargetType, &target.TargetID, &target.Name, &checkedAt); err != nil {
+			continue
+		}
+		if checkedAt.Valid {
+			target.CheckedAt = &checkedAt.Time
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// GetFollowedTitles retrieves every title recorded for a person or company
+func (d *Database) GetFollowedTitles(targetType string, targetID int) ([]models.FollowedTitle, error) {
+	query := `
+		SELECT target_type, target_id, content_id, content_type, title,
+			COALESCE(release_date, ''), COALESCE(poster_path, ''), COALESCE(role, ''), discovered_at
+		FROM followed_titles
+		WHERE target_type = ? AND target_id = ?
+	`
+
+	rows, err := d.query("GetFollowedTitles", query, targetType, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query followed titles: %w", err)
+	}
+	defer rows.Close()
+
+	var titles []models.FollowedTitle
+	for rows.Next() {
+		var title models.FollowedTitle
+		if err := rows.Scan(&title.TargetType, &title.TargetID, &title.ContentID, &title.ContentType, &title.Title,
+			&title.ReleaseDate, &title.PosterPath, &title.Role, &title.DiscoveredAt); err != nil {
+			continue
+		}
+		titles = append(titles, title)
+	}
+	return titles, nil
+}
+
+// RecordFollowScan stores the titles a scan found for a target and marks
+// it checked. Baseline titles were already credited when the target was
+// first scanned and never show up in release feeds. A scan of a target
+// its last follower dropped meanwhile is discarded.
+func (d *Database) RecordFollowScan(target models.FollowTarget, baseline, announced []models.FollowedTitle) (err error) {
+	start := time.Now()
+	defer func() { observeQuery("RecordFollowScan", start, err) }()
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin follow scan: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE follow_targets SET checked_at = CURRENT_TIMESTAMP WHERE target_type = ? AND target_id = ?`,
+		target.TargetType, target.TargetID)
+	if err != nil {
+		return fmt.Errorf("failed to mark follow target checked: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil
+	}
+
+	for _, group := range []struct {
+		titles   []models.FollowedTitle
+		baseline bool
+	}{{baseline, true}, {announced, false}} {
+		for _, title := range group.titles {
+			_, err = tx.Exec(`
+				INSERT OR IGNORE INTO followed_titles
+					(target_type, target_id, content_id, content_type, title, release_date, poster_path, role, baseline, discovered_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			`, target.TargetType, target.TargetID, title.ContentID, title.ContentType, title.Title,
+				title.ReleaseDate, title.PosterPath, title.Role, group.baseline)
+			if err != nil {
+				return fmt.Errorf("failed to save followed title: %w", err)
+			}
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit follow scan: %w", err)
+	}
+	return nil
+}
+
+// GetFollowedReleases retrieves titles announced by the people and
+// companies a user follows since they started following them, newest first
+func (d *Database) GetFollowedReleases(userID string, limit int) ([]models.FollowedTitle, error) {
+	query := `
+		SELECT ft.target_type, ft.target_id, t.name, ft.content_id, ft.content_type, ft.title,
+			COALESCE(ft.release_date, ''), COALESCE(ft.poster_path, ''), COALESCE(ft.role, ''), ft.discovered_at
+		FROM follows f
+		JOIN follow_targets t ON t.target_type = f.target_type AND t.target_id = f.target_id
+		JOIN followed_titles ft ON ft.target_type = f.target_type AND ft.target_id = f.target_id
+		WHERE f.user_id = ? AND NOT ft.baseline AND ft.discovered_at >= f.created_at
+		ORDER BY ft.discovered_at DESC, ft.title
+		LIMIT ?
+	`
+
+	rows, err := d.query("GetFollowedReleases", query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query followed releases: %w", err)
+	}
+	defer rows.Close()
+
+	releases := []models.FollowedTitle{}
+	for rows.Next() {
+		var title models.FollowedTitle
+		if err := rows.Scan(&title.TargetType, &title.TargetID, &title.TargetName, &title.ContentID, &title.ContentType, &title.Title,
+			&title.ReleaseDate, &title.PosterPath, &title.Role, &title.DiscoveredAt); err != nil {
+			continue
+		}
+		releases = append(releases, title)
+	}
+	return releases, nil
+}
+
+func scanFollow(row rowScanner) (*models.Follow, error) {
+	var follow models.Follow
+	var checkedAt sql.NullTime
+	if err := row.Scan(&follow.UserID, &follow.TargetType, &follow.TargetID, &follow.Name, &checkedAt, &follow.CreatedAt); err != nil {
+		return nil, err
+	}
+	if checkedAt.Valid {
+		follow.CheckedAt = &checkedAt.Time
+	}
+	return &follow, nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"binge-base/models"
+)
+
+func TestFollowsModifiedAt(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	modified, err := db.FollowsModifiedAt("alice")
+	if err != nil || !modified.IsZero() {
+		t.Fatalf("no follows: %v %v, want zero", modified, err)
+	}
+
+	target := models.FollowTarget{TargetType: "person", TargetID: 525, Name: "Christopher Nolan"}
+	if _, err := db.AddFollow("alice", target); err != nil {
+		t.Fatal(err)
+	}
+	// Back-date the follow so later changes are visible at second precision
+	if _, err := db.DB.Exec(`UPDATE follows SET created_at = '2026-01-01 00:00:00'`); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := db.FollowsModifiedAt("alice"); !modified.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("after follow: %v", modified)
+	}
+
+	target.Name = "C. Nolan"
+	if _, err := db.AddFollow("bob", target); err != nil {
+		t.Fatal(err)
+	}
+	renamed, _ := db.FollowsModifiedAt("alice")
+	if time.Since(renamed) > time.Minute {
+		t.Errorf("rename by another follower not dated: %v", renamed)
+	}
+
+	if err := db.DeleteFollow("alice", "person", 525); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := db.FollowsModifiedAt("alice"); time.Since(modified) > time.Minute {
+		t.Errorf("removal not dated: %v", modified)
+	}
+}
+
+func TestDeleteFollowForgetsTarget(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	target := models.FollowTarget{TargetType: "company", TargetID: 420, Name: "Studio"}
+	for _, user := range []string{"alice", "bob"} {
+		if _, err := db.AddFollow(user, target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	titles := []models.FollowedTitle{{ContentID: 1, ContentType: "movie", Title: "One"}}
+	if err := db.RecordFollowScan(target, titles, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func() (n int) {
+		if err := db.DB.QueryRow(`SELECT COUNT(*) FROM followed_titles`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if err := db.DeleteFollow("alice", "company", 420); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("%d titles with a follower left, want 1", n)
+	}
+	if err := db.DeleteFollow("bob", "company", 420); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("%d titles after the last unfollow", n)
+	}
+
+	// A scan that was in flight when the last follower left is discarded
+	if err := db.RecordFollowScan(target, titles, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("%d titles recorded for a forgotten target", n)
+	}
+	if err := db.DeleteFollow("bob", "company", 420); err != sql.ErrNoRows {
+		t.Errorf("second removal: %v, want sql.ErrNoRows", err)
+	}
+}
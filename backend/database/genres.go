@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"binge-base/models"
+)
+
+// GetGenres lists the stored genre catalog by name. It is empty until the
+// first refresh from TMDB.
+func (d *Database) GetGenres() ([]models.Genre, error) {
+	query := `
+		SELECT id, name, movie, tv
+		FROM genres
+		WHERE movie OR tv
+		ORDER BY name
+	`
+
+	rows, err := d.query("GetGenres", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get genres: %w", err)
+	}
+	defer rows.Close()
+
+	genres := []models.Genre{}
+	for rows.Next() {
+		var genre models.Genre
+		var movie, tv bool
+		if err := rows.Scan(&genre.ID, &genre.Name, &movie, &tv); err != nil {
+			return nil, fmt.Errorf("failed to scan genre: %w", err)
+		}
+		if movie {
+			genre.MediaTypes = append(genre.MediaTypes, "movie")
+		}
+		if tv {
+			genre.MediaTypes = append(genre.MediaTypes, "tv")
+		}
+		genres = append(genres, genre)
+	}
+	return genres, rows.Err()
+}
+
+// ReplaceGenres stores a freshly fetched catalog. Genres missing from it
+// keep their row, since movie_genres and tv_genres may reference them, but
+// lose their media-type flags and drop out of GetGenres.
+func (d *Database) ReplaceGenres(genres []models.Genre) (err error) {
+	start := time.Now()
+	defer func() { observeQuery("ReplaceGenres", start, err) }()
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin genre update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`UPDATE genres SET movie = FALSE, tv = FALSE`); err != nil {
+		return fmt.Errorf("failed to reset genres: %w", err)
+	}
+	for _, genre := range genres {
+		_, err = tx.Exec(`
+			INSERT INTO genres (id, name, movie, tv, updated_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET
+				name = excluded.name,
+				movie = excluded.movie,
+				tv = excluded.tv,
+				updated_at = excluded.updated_at
+		`, genre.ID, genre.Name, genre.HasMediaType("movie"), genre.HasMediaType("tv"))
+		if err != nil {
+			return fmt.Errorf("failed to save genre %d: %w", genre.ID, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit genres: %w", err)
+	}
+	return nil
+}
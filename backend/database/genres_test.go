@@ -0,0 +1,33 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"binge-base/models"
+)
+
+func TestReplaceGenresReusesStaleName(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	movie := []string{"movie"}
+	if err := db.ReplaceGenres([]models.Genre{{ID: 878, Name: "Science Fiction", MediaTypes: movie}}); err != nil {
+		t.Fatal(err)
+	}
+	// TMDB retires 878 and gives its name to a new genre
+	if err := db.ReplaceGenres([]models.Genre{{ID: 900, Name: "Science Fiction", MediaTypes: movie}}); err != nil {
+		t.Fatalf("rename onto a stale genre's name: %v", err)
+	}
+
+	genres, err := db.GetGenres()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(genres) != 1 || genres[0].ID != 900 {
+		t.Errorf("catalog %+v, want only genre 900", genres)
+	}
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"binge-base/metrics"
+)
+
+var queryDuration = metrics.NewHistogramVec("bingebase_db_query_duration_seconds",
+	"Latency of SQLite statements by database operation. Queries are timed until the first row is available.",
+	metrics.DBBuckets, "op", "outcome")
+
+func (d *Database) exec(op, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := d.DB.Exec(query, args...)
+	observeQuery(op, start, err)
+	return result, err
+}
+
+func (d *Database) query(op, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.DB.Query(query, args...)
+	observeQuery(op, start, err)
+	return rows, err
+}
+
+func (d *Database) queryRow(op, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := d.DB.QueryRow(query, args...)
+	observeQuery(op, start, row.Err())
+	return row
+}
+
+func observeQuery(op string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	queryDuration.Observe(time.Since(start).Seconds(), op, outcome)
+}
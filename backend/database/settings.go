@@ -0,0 +1,49 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"binge-base/models"
+)
+
+// GetUserSettings retrieves a user's settings, falling back to the defaults
+// for users who never saved any
+func (d *Database) GetUserSettings(userID string) (*models.UserSettings, error) {
+	query := `
+		SELECT user_id, include_adult, updated_at
+		FROM user_settings
+		WHERE user_id = ?
+	`
+
+	settings := models.UserSettings{}
+	var updatedAt sql.NullTime
+	err := d.queryRow("GetUserSettings", query, userID).Scan(&settings.UserID, &settings.IncludeAdult, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.UserSettings{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	if updatedAt.Valid {
+		settings.UpdatedAt = &updatedAt.Time
+	}
+	return &settings, nil
+}
+
+// SaveUserSettings creates or replaces a user's settings
+func (d *Database) SaveUserSettings(settings models.UserSettings) (*models.UserSettings, error) {
+	query := `
+		INSERT INTO user_settings (user_id, include_adult, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET
+			include_adult = excluded.include_adult,
+			updated_at = excluded.updated_at
+	`
+
+	if _, err := d.exec("SaveUserSettings", query, settings.UserID, settings.IncludeAdult); err != nil {
+		return nil, fmt.Errorf("failed to save user settings: %w", err)
+	}
+	return d.GetUserSettings(settings.UserID)
+}
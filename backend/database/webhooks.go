@@ -0,0 +1,240 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"binge-base/models"
+)
+
+// CreateWebhook registers a new webhook for a user
+func (d *Database) CreateWebhook(userID, url, secret string, events []string) (*models.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, events, active, created_at)
+		VALUES (?, ?, ?, ?, TRUE, CURRENT_TIMESTAMP)
+	`
+
+	result, err := d.exec("CreateWebhook", query, userID, url, secret, strings.Join(events, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook id: %w", err)
+	}
+
+	return d.GetWebhook(userID, int(id))
+}
+
+// GetWebhook retrieves a single webhook owned by a user
+func (d *Database) GetWebhook(userID string, webhookID int) (*models.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, secret, events, active, created_at
+		FROM webhooks
+		WHERE user_id = ? AND id = ?
+	`
+
+	webhook, err := scanWebhook(d.queryRow("GetWebhook", query, userID, webhookID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetWebhooks retrieves all webhooks registered by a user
+func (d *Database) GetWebhooks(userID string) ([]models.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, secret, events, active, created_at
+		FROM webhooks
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+	`
+	return d.queryWebhooks("GetWebhooks", query, userID)
+}
+
+// GetWebhooksForEvent retrieves the active webhooks of a user subscribed to an event
+func (d *Database) GetWebhooksForEvent(userID, event string) ([]models.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, secret, events, active, created_at
+		FROM webhooks
+		WHERE user_id = ? AND active = TRUE
+	`
+
+	webhooks, err := d.queryWebhooks("GetWebhooksForEvent", query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscribed []models.Webhook
+	for _, webhook := range webhooks {
+		for _, e := range webhook.Events {
+			if e == event || e == "*" {
+				subscribed = append(subscribed, webhook)
+				break
+			}
+		}
+	}
+	return subscribed, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (d *Database) DeleteWebhook(userID string, webhookID int) error {
+	result, err := d.exec("DeleteWebhook", `DELETE FROM webhooks WHERE user_id = ? AND id = ?`, userID, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := d.exec("DeleteWebhook", `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// RecordWebhookDelivery stores the outcome of a delivery attempt
+func (d *Database) RecordWebhookDelivery(delivery *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event, attempt, status_code, success, error, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	_, err := d.exec("RecordWebhookDelivery", query, delivery.WebhookID, delivery.EventID, delivery.Event, delivery.Attempt,
+		delivery.StatusCode, delivery.Success, delivery.Error, delivery.DurationMS)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries retrieves the most recent delivery attempts for a user's webhook
+func (d *Database) GetWebhookDeliveries(userID string, webhookID int, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT wd.id, wd.webhook_id, wd.event_id, wd.event, wd.attempt, wd.status_code,
+			wd.success, wd.error, wd.duration_ms, wd.created_at
+		FROM webhook_deliveries wd
+		JOIN webhooks w ON w.id = wd.webhook_id
+		WHERE w.user_id = ? AND wd.webhook_id = ?
+		ORDER BY wd.id DESC
+		LIMIT ?
+	`
+
+	rows, err := d.query("GetWebhookDeliveries", query, userID, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var statusCode sql.NullInt64
+		var errMsg sql.NullString
+		var durationMS sql.NullInt64
+		if err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.Event, &delivery.Attempt,
+			&statusCode, &delivery.Success, &errMsg, &durationMS, &delivery.CreatedAt); err != nil {
+			continue
+		}
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.Error = errMsg.String
+		delivery.DurationMS = durationMS.Int64
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// GetUnwatchedWatchlist retrieves unwatched watchlist items across all users
+func (d *Database) GetUnwatchedWatchlist() ([]models.WatchlistItem, error) {
+	query := `
+		SELECT id, user_id, content_id, content_type, is_watched, added_at
+		FROM watchlist
+		WHERE is_watched = FALSE
+	`
+
+	rows, err := d.query("GetUnwatchedWatchlist", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unwatched watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.WatchlistItem
+	for rows.Next() {
+		var item models.WatchlistItem
+		if err := rows.Scan(&item.ID, &item.UserID, &item.ContentID, &item.ContentType, &item.IsWatched, &item.AddedAt); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// MarkReleaseNotified records that a user was notified about a release date.
+// It reports false if the notification had already been recorded.
+func (d *Database) MarkReleaseNotified(userID string, contentID int, contentType, releaseDate string) (bool, error) {
+	query := `
+		INSERT OR IGNORE INTO release_notifications (user_id, content_id, content_type, release_date)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := d.exec("MarkReleaseNotified", query, userID, contentID, contentType, releaseDate)
+	if err != nil {
+		return false, fmt.Errorf("failed to record release notification: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record release notification: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// UnmarkReleaseNotified forgets a recorded release notification so it is
+// sent again
+func (d *Database) UnmarkReleaseNotified(userID string, contentID int, contentType, releaseDate string) error {
+	query := `
+		DELETE FROM release_notifications
+		WHERE user_id = ? AND content_id = ? AND content_type = ? AND release_date = ?
+	`
+
+	if _, err := d.exec("UnmarkReleaseNotified", query, userID, contentID, contentType, releaseDate); err != nil {
+		return fmt.Errorf("failed to reset release notification: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) queryWebhooks(op, query string, args ...interface{}) ([]models.Webhook, error) {
+	rows, err := d.query(op, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			continue
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var events string
+	if err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+	webhook.Events = strings.Split(events, ",")
+	return &webhook, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"binge-base/logging"
+	"binge-base/models"
+	"binge-base/router"
+)
+
+// Season details handler
+func (s *Server) seasonHandler(w http.ResponseWriter, r *http.Request) {
+	tvID, seasonNumber, ok := s.seasonParams(w, r)
+	if !ok {
+		return
+	}
+	season, err := s.tmdbService.GetSeasonDetails(r.Context(), tvID, seasonNumber)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Season not found", "Failed to fetch season details")
+		return
+	}
+	if !s.annotateEpisodes(w, r, tvID, season.Episodes) {
+		return
+	}
+	s.sendData(w, season)
+}
+
+// Episode details handler
+func (s *Server) episodeHandler(w http.ResponseWriter, r *http.Request) {
+	tvID, seasonNumber, ok := s.seasonParams(w, r)
+	if !ok {
+		return
+	}
+	episodeNumber, err := router.IntParam(r, "episode")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid episode number")
+		return
+	}
+	episode, err := s.tmdbService.GetEpisodeDetails(r.Context(), tvID, seasonNumber, episodeNumber)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Episode not found", "Failed to fetch episode details")
+		return
+	}
+	episodes := []models.Episode{*episode}
+	if !s.annotateEpisodes(w, r, tvID, episodes) {
+		return
+	}
+	s.sendData(w, episodes[0])
+}
+
+// seasonParams reads the show ID and season number; season 0 holds specials
+func (s *Server) seasonParams(w http.ResponseWriter, r *http.Request) (tvID, seasonNumber int, ok bool) {
+	tvID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid TV show ID")
+		return 0, 0, false
+	}
+	seasonNumber, err = strconv.Atoi(router.Param(r, "season"))
+	if err != nil || seasonNumber < 0 {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid season number")
+		return 0, 0, false
+	}
+	return tvID, seasonNumber, true
+}
+
+// annotateEpisodes marks episodes with the user's watched state. Users who
+// don't track episodes of the show get no annotation rather than a list of
+// unwatched episodes.
+func (s *Server) annotateEpisodes(w http.ResponseWriter, r *http.Request, tvID int, episodes []models.Episode) bool {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	tracked, err := s.db.GetWatchedEpisodes(userID, tvID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get watched episodes")
+		return false
+	}
+	if len(tracked) == 0 {
+		return true
+	}
+
+	watched := make(map[[2]int]bool, len(tracked))
+	for _, episode := range tracked {
+		watched[[2]int{episode.SeasonNumber, episode.EpisodeNumber}] = true
+	}
+	for i := range episodes {
+		state := watched[[2]int{episodes[i].SeasonNumber, episodes[i].EpisodeNumber}]
+		episodes[i].Watched = &state
+	}
+	return true
+}
+
+type updateEpisodeRequest struct {
+	UserID        string `json:"user_id"`
+	TVID          int    `json:"tv_id"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	Watched       bool   `json:"watched"`
+}
+
+// Marks a single episode watched or unwatched, independently of the show's
+// watchlist entry
+func (s *Server) updateWatchedEpisodeHandler(w http.ResponseWriter, r *http.Request) {
+	var request updateEpisodeRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	if request.TVID <= 0 || request.SeasonNumber < 0 || request.EpisodeNumber <= 0 {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "tv_id, season_number and episode_number must identify an episode")
+		return
+	}
+	if err := s.db.SetEpisodeWatched(request.UserID, request.TVID, request.SeasonNumber, request.EpisodeNumber, request.Watched); err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to update watched episode")
+		return
+	}
+	s.sendMessage(w, "Episode watch status updated")
+}
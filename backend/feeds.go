@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"binge-base/logging"
+	"binge-base/models"
+)
+
+const (
+	atomNamespace  = "http://www.w3.org/2005/Atom"
+	feedEntryLimit = 40
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Summary string      `xml:"summary,omitempty"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedItem is the media-type agnostic input for building an Atom entry
+type feedItem struct {
+	id          int
+	mediaType   string
+	title       string
+	overview    string
+	posterPath  string
+	releaseDate string
+	verb        string
+	updated     time.Time
+}
+
+// Trending Atom feed handler
+func (s *Server) trendingFeedHandler(w http.ResponseWriter, r *http.Request) {
+	movies, err := s.tmdbService.GetTrendingMovies(r.Context(), 1)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "", "Failed to fetch trending movies")
+		return
+	}
+	tv, err := s.tmdbService.GetTrendingTVShows(r.Context(), 1)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "", "Failed to fetch trending TV shows")
+		return
+	}
+
+	// TMDB recomputes the weekly trending lists once a day, so the feed
+	// changes at most daily and entries share the feed's timestamp
+	updated := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var items []feedItem
+	for _, result := range append(movies.Results, tv.Results...) {
+		items = append(items, trendingFeedItem(result, updated))
+	}
+
+	base := requestBaseURL(r)
+	feed := atomFeed{
+		ID:      "tag:bingebase,2024:feeds/trending",
+		Title:   "BingeBase – Trending this week",
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: base + r.URL.Path},
+			{Rel: "alternate", Type: "text/html", Href: base + "/trending"},
+		},
+		Author: atomAuthor{Name: "BingeBase"},
+	}
+	for _, item := range items {
+		feed.Entries = append(feed.Entries, item.entry(base, "trending"))
+	}
+
+	// The list can change during the day while the timestamp stays put, so
+	// only the ETag validates the feed
+	s.sendAtom(w, r, feed, time.Time{}, "public, max-age=900")
+}
+
+// User activity Atom feed handler
+func (s *Server) activityFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+
+	activity, err := s.db.GetWatchlistActivity(userID, feedEntryLimit)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get watchlist activity")
+		return
+	}
+	// Removing or unwatching an item takes its date out of the entries, so
+	// the feed is dated by the watchlist's last change instead
+	updated, err := s.db.WatchlistModifiedAt(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get watchlist activity")
+		return
+	}
+
+	var items []feedItem
+	for _, wi := range activity {
+		item := feedItem{id: wi.ContentID, mediaType: wi.ContentType, verb: "added", updated: wi.AddedAt.UTC()}
+		if wi.IsWatched && wi.WatchedAt != nil {
+			item.verb = "watched"
+			item.updated = wi.WatchedAt.UTC()
+		}
+		s.fillFeedItemDetails(r.Context(), &item)
+		items = append(items, item)
+	}
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	updated = updated.UTC()
+
+	base := requestBaseURL(r)
+	feed := atomFeed{
+		ID:      "tag:bingebase,2024:feeds/activity/" + userID,
+		Title:   "BingeBase – Recent activity for " + userID,
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: base + r.URL.RequestURI()},
+			{Rel: "alternate", Type: "text/html", Href: base + "/watchlist"},
+		},
+		Author: atomAuthor{Name: userID},
+	}
+	for _, item := range items {
+		feed.Entries = append(feed.Entries, item.entry(base, "activity/"+userID))
+	}
+
+	s.sendAtom(w, r, feed, updated, "private, max-age=60")
+}
+
+// fillFeedItemDetails looks up title, overview and poster for a watchlist item.
+// Lookups that fail leave a placeholder title so the entry is still emitted.
+func (s *Server) fillFeedItemDetails(ctx context.Context, item *feedItem) {
+	switch item.mediaType {
+	case "movie":
+		if movie, err := s.tmdbService.GetMovieDetails(ctx, item.id); err == nil {
+			item.title, item.overview, item.posterPath, item.releaseDate = movie.Title, movie.Overview, movie.PosterPath, movie.ReleaseDate
+		}
+	case "tv":
+		if show, err := s.tmdbService.GetTVDetails(ctx, item.id); err == nil {
+			item.title, item.overview, item.posterPath, item.releaseDate = show.Name, show.Overview, show.PosterPath, show.FirstAirDate
+		}
+	}
+	if item.title == "" {
+		item.title = fmt.Sprintf("%s #%d", item.mediaType, item.id)
+	}
+}
+
+func (item feedItem) entry(base, scope string) atomEntry {
+	title := item.title
+	if item.verb != "" {
+		title = fmt.Sprintf("%s %s", capitalize(item.verb), item.title)
+	}
+	link := fmt.Sprintf("%s/%s/%d", base, item.mediaType, item.id)
+
+	id := fmt.Sprintf("tag:bingebase,2024:%s/%s/%d", scope, item.mediaType, item.id)
+	if item.verb != "" {
+		id += "/" + item.verb
+	}
+
+	entry := atomEntry{
+		ID:      id,
+		Title:   title,
+		Updated: item.updated.Format(time.RFC3339),
+		Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: link}},
+		Summary: item.overview,
+	}
+
+	var body string
+	if item.posterPath != "" {
+		poster := models.ImageURL(item.posterPath, "w500")
+		entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Type: "image/jpeg", Href: poster})
+		body += fmt.Sprintf(`<p><img src="%s" alt="%s"/></p>`, html.EscapeString(poster), html.EscapeString(item.title))
+	}
+	if item.releaseDate != "" {
+		body += fmt.Sprintf("<p><strong>Released:</strong> %s</p>", html.EscapeString(item.releaseDate))
+	}
+	if item.overview != "" {
+		body += fmt.Sprintf("<p>%s</p>", html.EscapeString(item.overview))
+	}
+	entry.Content = atomContent{Type: "html", Body: body}
+	return entry
+}
+
+func trendingFeedItem(result models.ResultItem, updated time.Time) feedItem {
+	return feedItem{
+		id:          result.ID,
+		mediaType:   result.MediaType,
+		title:       result.Title,
+		overview:    result.Overview,
+		posterPath:  result.PosterPath,
+		releaseDate: result.ReleaseDate,
+		updated:     updated,
+	}
+}
+
+// sendAtom writes an Atom feed, answering conditional GETs with 304 when the
+// client's ETag or Last-Modified is still current. A zero lastUpdate sends
+// no Last-Modified, leaving the ETag as the only validator.
+func (s *Server) sendAtom(w http.ResponseWriter, r *http.Request, feed atomFeed, lastUpdate time.Time, cacheControl string) {
+	feed.Xmlns = atomNamespace
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to encode Atom feed", "error", err)
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to build feed")
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := lastUpdate.UTC().Truncate(time.Second)
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("ETag", etag)
+	if !lastUpdate.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+
+	// If-Modified-Since only counts when If-None-Match is absent
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagMatches(inm, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastUpdate.IsZero() && !lastModified.After(ims) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(body); err != nil {
+		logging.FromContext(r.Context()).Warn("failed to write Atom feed", "error", err)
+	}
+}
+
+// requestBaseURL reconstructs the public origin of the request, honoring
+// the proxy headers set by Railway
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendAtomConditionalRequests(t *testing.T) {
+	s := &Server{}
+	feed := atomFeed{ID: "tag:test", Title: "Test"}
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	get := func(lastUpdate time.Time, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/feeds/test.atom", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		s.sendAtom(w, r, feed, lastUpdate, "public, max-age=60")
+		return w
+	}
+
+	first := get(updated, nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Last-Modified") == "" {
+		t.Fatalf("unexpected first response: %d %v", first.Code, first.Header())
+	}
+
+	tests := []struct {
+		name       string
+		lastUpdate time.Time
+		headers    map[string]string
+		want       int
+	}{
+		{"exact etag", updated, map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"weak etag", updated, map[string]string{"If-None-Match": "W/" + etag}, http.StatusNotModified},
+		{"etag in list", updated, map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+		{"wildcard", updated, map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"stale etag", updated, map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"stale etag wins over current date", updated, map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": updated.Format(http.TimeFormat),
+		}, http.StatusOK},
+		{"current date", updated, map[string]string{"If-Modified-Since": updated.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"older date", updated, map[string]string{"If-Modified-Since": updated.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"date without Last-Modified", time.Time{}, map[string]string{"If-Modified-Since": updated.Format(http.TimeFormat)}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := get(tt.lastUpdate, tt.headers).Code; got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if lm := get(time.Time{}, nil).Header().Get("Last-Modified"); lm != "" {
+		t.Errorf("expected no Last-Modified without a timestamp, got %q", lm)
+	}
+}
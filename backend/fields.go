@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"binge-base/models"
+)
+
+// fieldsFull requests complete details in ?fields=
+const fieldsFull = "full"
+
+// fieldSet is the parsed ?fields= parameter: a comma-separated list of
+// top-level fields, or "full". Without it, lists of titles carry only a
+// TitleSummary. id and media_type are always included so results stay
+// addressable.
+type fieldSet struct {
+	full  bool
+	names []string
+}
+
+func parseFields(r *http.Request) fieldSet {
+	var f fieldSet
+	for _, name := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+		case fieldsFull:
+			f.full = true
+		default:
+			f.names = append(f.names, name)
+		}
+	}
+	return f
+}
+
+// sparse reports whether specific fields were requested
+func (f fieldSet) sparse() bool {
+	return !f.full && len(f.names) > 0
+}
+
+// details picks the representation of one title's details. Requested
+// fields may come from the full details or the summary, so ?fields=year
+// works for TV shows too.
+func (f fieldSet) details(full interface{}, summary models.TitleSummary) interface{} {
+	switch {
+	case f.full:
+		return full
+	case !f.sparse():
+		return summary
+	}
+	fields := jsonFields(summary)
+	for key, value := range jsonFields(full) {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return f.pick(fields)
+}
+
+// items trims search or trending results to the requested fields
+func (f fieldSet) items(items []models.ResultItem) interface{} {
+	if !f.sparse() {
+		return items
+	}
+	picked := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		picked[i] = f.pick(jsonFields(item))
+	}
+	return picked
+}
+
+// pick keeps the requested fields; unknown names are ignored
+func (f fieldSet) pick(fields map[string]interface{}) map[string]interface{} {
+	picked := make(map[string]interface{}, len(f.names)+2)
+	for _, name := range append([]string{"id", "media_type"}, f.names...) {
+		if value, ok := fields[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+// jsonFields returns the top-level fields of v's JSON encoding
+func jsonFields(v interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if body, err := json.Marshal(v); err == nil {
+		json.Unmarshal(body, &fields)
+	}
+	return fields
+}
+
+func movieSummary(movie *models.Movie) models.TitleSummary {
+	return models.TitleSummary{
+		ID:          movie.ID,
+		MediaType:   models.MediaTypeMovie,
+		Title:       movie.Title,
+		Year:        models.ReleaseYear(movie.ReleaseDate),
+		PosterPath:  movie.PosterPath,
+		VoteAverage: movie.VoteAverage,
+		Runtime:     movie.Runtime,
+	}
+}
+
+func tvSummary(show *models.TVShow) models.TitleSummary {
+	return models.TitleSummary{
+		ID:          show.ID,
+		MediaType:   models.MediaTypeTV,
+		Title:       show.Name,
+		Year:        models.ReleaseYear(show.FirstAirDate),
+		PosterPath:  show.PosterPath,
+		VoteAverage: show.VoteAverage,
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"binge-base/logging"
+	"binge-base/models"
+)
+
+// Follow handlers
+func (s *Server) getFollowsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	// Dated before reading, so a change in between makes the date too old
+	// rather than hiding the change
+	modified, err := s.db.FollowsModifiedAt(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get follows")
+		return
+	}
+	follows, err := s.db.GetFollows(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get follows")
+		return
+	}
+	setLastModified(w, modified)
+	s.sendData(w, follows)
+}
+
+type followRequest struct {
+	UserID     string `json:"user_id"`
+	TargetType string `json:"target_type"`
+	TargetID   int    `json:"target_id"`
+}
+
+// Following checks the person or company exists and stores its name. A
+// target nobody followed before is baselined right away, so titles
+// announced from now on show up in the user's feed after the next scan.
+func (s *Server) createFollowHandler(w http.ResponseWriter, r *http.Request) {
+	var request followRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	if request.TargetID <= 0 {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid target ID")
+		return
+	}
+
+	target := models.FollowTarget{TargetType: request.TargetType, TargetID: request.TargetID}
+	switch request.TargetType {
+	case models.FollowPerson:
+		person, err := s.tmdbService.GetPersonDetails(r.Context(), request.TargetID)
+		if err != nil {
+			s.sendUpstreamError(w, r, err, "Person not found", "Failed to fetch person details")
+			return
+		}
+		target.Name = person.Name
+	case models.FollowCompany:
+		company, err := s.tmdbService.GetCompanyDetails(r.Context(), request.TargetID)
+		if err != nil {
+			s.sendUpstreamError(w, r, err, "Company not found", "Failed to fetch company details")
+			return
+		}
+		target.Name = company.Name
+	default:
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "target_type must be person or company")
+		return
+	}
+
+	follow, err := s.db.AddFollow(request.UserID, target)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to follow")
+		return
+	}
+	// Left to the next scan if TMDB can't be reached now
+	if follow.CheckedAt == nil {
+		if err := s.followWatcher.Baseline(r.Context(), follow.FollowTarget); err != nil {
+			logging.FromContext(r.Context()).Warn("failed to baseline followed titles", "target_type", target.TargetType, "target_id", target.TargetID, "error", err)
+		} else if baselined, err := s.db.GetFollow(request.UserID, target.TargetType, target.TargetID); err == nil {
+			follow = baselined
+		}
+	}
+	s.sendJSON(w, http.StatusCreated, models.APIResponse{Success: true, Data: follow})
+}
+
+func (s *Server) deleteFollowHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	targetID, err := strconv.Atoi(r.URL.Query().Get("target_id"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid target ID")
+		return
+	}
+	if err := s.db.DeleteFollow(userID, r.URL.Query().Get("target_type"), targetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.sendError(w, http.StatusNotFound, models.ErrCodeNotFound, "Follow not found")
+			return
+		}
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to unfollow")
+		return
+	}
+	s.sendMessage(w, "Unfollowed")
+}
+
+// New titles from followed people and companies
+func (s *Server) followedReleasesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	releases, err := s.db.GetFollowedReleases(userID, limit)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get new titles")
+		return
+	}
+	s.sendData(w, releases)
+}
+
+// "New from people you follow" Atom feed handler
+func (s *Server) followingFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+
+	releases, err := s.db.GetFollowedReleases(userID, feedEntryLimit)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get new titles")
+		return
+	}
+	// Unfollowing takes entries out without a newer one replacing them, so
+	// the feed is dated by the follows list's last change as well
+	updated, err := s.db.FollowsModifiedAt(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get new titles")
+		return
+	}
+	updated = updated.UTC()
+	if updated.IsZero() {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	var items []feedItem
+	for _, release := range releases {
+		item := feedItem{
+			id:          release.ContentID,
+			mediaType:   release.ContentType,
+			title:       release.Title,
+			overview:    followCredit(release),
+			posterPath:  release.PosterPath,
+			releaseDate: release.ReleaseDate,
+			updated:     release.DiscoveredAt.UTC(),
+		}
+		if item.updated.After(updated) {
+			updated = item.updated
+		}
+		items = append(items, item)
+	}
+
+	base := requestBaseURL(r)
+	feed := atomFeed{
+		ID:      "tag:bingebase,2024:feeds/following/" + userID,
+		Title:   "BingeBase – New from people you follow",
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: base + r.URL.RequestURI()},
+		},
+		Author: atomAuthor{Name: userID},
+	}
+	for _, item := range items {
+		feed.Entries = append(feed.Entries, item.entry(base, "following/"+userID))
+	}
+
+	s.sendAtom(w, r, feed, updated, "private, max-age=300")
+}
+
+// followCredit describes who a followed title is from, such as
+// "Greta Gerwig (Director)"
+func followCredit(release models.FollowedTitle) string {
+	if release.Role == "" {
+		return "From " + release.TargetName
+	}
+	return fmt.Sprintf("From %s (%s)", release.TargetName, release.Role)
+}
@@ -1,8 +1,9 @@
-module binge-base
-
-go 1.21
-
-require (
-	github.com/joho/godotenv v1.4.0
-	github.com/mattn/go-sqlite3 v1.14.17
-)
+module binge-base
+
+go 1.21
+
+require (
+	github.com/andybalholm/brotli v1.1.1
+	github.com/joho/godotenv v1.4.0
+	github.com/mattn/go-sqlite3 v1.14.17
+)
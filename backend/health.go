@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"binge-base/database"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+var startedAt = time.Now()
+
+// probeTimeout bounds a single reachability check
+const probeTimeout = 5 * time.Second
+
+// dependencyProbe runs a reachability check and caches its result, so
+// frequent readiness polls don't spend upstream API quota
+type dependencyProbe struct {
+	ttl   time.Duration
+	check func(ctx context.Context) error
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+	running   chan struct{} // closed when the check in flight finishes
+}
+
+func newDependencyProbe(ttl time.Duration, check func(ctx context.Context) error) *dependencyProbe {
+	return &dependencyProbe{ttl: ttl, check: check}
+}
+
+// result returns the cached outcome, running the check when it is stale.
+// The check is detached from ctx so a cancelled health request can't
+// cache its cancellation as an outage; concurrent callers share one check.
+func (p *dependencyProbe) result(ctx context.Context) (time.Time, error) {
+	p.mu.Lock()
+	if !p.checkedAt.IsZero() && time.Since(p.checkedAt) <= p.ttl {
+		defer p.mu.Unlock()
+		return p.checkedAt, p.err
+	}
+	if p.running == nil {
+		p.running = make(chan struct{})
+		go p.run(p.running)
+	}
+	running := p.running
+	p.mu.Unlock()
+
+	select {
+	case <-running:
+	case <-ctx.Done():
+		return time.Now(), ctx.Err()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.checkedAt, p.err
+}
+
+func (p *dependencyProbe) run(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	err := p.check(ctx)
+	cancel()
+
+	p.mu.Lock()
+	p.err, p.checkedAt, p.running = err, time.Now(), nil
+	p.mu.Unlock()
+	close(done)
+}
+
+type dependencyStatus struct {
+	Status     string     `json:"status"`
+	Required   bool       `json:"required"`
+	Configured *bool      `json:"configured,omitempty"`
+	LatencyMS  *float64   `json:"latency_ms,omitempty"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Health responses aren't wrapped in the APIResponse envelope: probes only
+// look at the status code, and operators read them as-is
+type livenessReport struct {
+	Status        string `json:"status"`
+	UptimeSeconds int    `json:"uptime_seconds"`
+}
+
+type readinessReport struct {
+	Status        string                      `json:"status"`
+	ShuttingDown  bool                        `json:"shutting_down"`
+	Checks        map[string]dependencyStatus `json:"checks"`
+	Migrations    map[string]int              `json:"migrations"`
+	Build         map[string]string           `json:"build"`
+	UptimeSeconds int                         `json:"uptime_seconds"`
+}
+
+// Liveness handler: the process is up and serving requests
+func (s *Server) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	s.sendJSON(w, http.StatusOK, livenessReport{
+		Status:        "ok",
+		UptimeSeconds: int(time.Since(startedAt).Seconds()),
+	})
+}
+
+// Readiness handler: every required dependency is reachable. Answers 503
+// otherwise so the platform stops routing traffic here.
+func (s *Server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	ready := !s.shuttingDown.Load()
+	checks := map[string]dependencyStatus{}
+
+	dbStatus := dependencyStatus{Status: "up", Required: true}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	start := time.Now()
+	err := s.db.Ping(ctx)
+	cancel()
+	latency := float64(time.Since(start).Microseconds()) / 1000
+	dbStatus.LatencyMS = &latency
+	if err != nil {
+		dbStatus.Status, dbStatus.Error = "down", err.Error()
+		ready = false
+	}
+	checks["database"] = dbStatus
+
+	// TMDB backs every read endpoint; OMDB only adds extra ratings, so it
+	// only gates readiness once a key has been configured and probing it
+	// has been enabled
+	tmdbStatus := s.upstreamStatus(r.Context(), s.tmdbService.Configured(), s.tmdbProbe, true)
+	if tmdbStatus.Status != "up" {
+		ready = false
+	}
+	checks["tmdb"] = tmdbStatus
+
+	omdbStatus := s.upstreamStatus(r.Context(), s.omdbService.Configured(), s.omdbProbe, false)
+	if omdbStatus.Status == "down" {
+		ready = false
+	}
+	checks["omdb"] = omdbStatus
+
+	schemaVersion, err := s.db.SchemaVersion()
+	if err != nil {
+		ready = false
+	}
+
+	status, statusCode := "ok", http.StatusOK
+	if !ready {
+		status, statusCode = "unavailable", http.StatusServiceUnavailable
+	}
+	s.sendJSON(w, statusCode, readinessReport{
+		Status:       status,
+		ShuttingDown: s.shuttingDown.Load(),
+		Checks:       checks,
+		Migrations: map[string]int{
+			"current": schemaVersion,
+			"latest":  database.LatestSchemaVersion(),
+		},
+		Build:         buildInfo(),
+		UptimeSeconds: int(time.Since(startedAt).Seconds()),
+	})
+}
+
+func (s *Server) upstreamStatus(ctx context.Context, configured bool, probe *dependencyProbe, required bool) dependencyStatus {
+	status := dependencyStatus{Required: required, Configured: &configured}
+	if !configured {
+		status.Status = "not_configured"
+		return status
+	}
+	if probe == nil {
+		status.Status = "unchecked"
+		return status
+	}
+
+	checkedAt, err := probe.result(ctx)
+	status.CheckedAt = &checkedAt
+	status.Status = "up"
+	if err != nil {
+		status.Status, status.Error = "down", err.Error()
+	}
+	return status
+}
+
+func buildInfo() map[string]string {
+	info := map[string]string{
+		"version":    version,
+		"go_version": runtime.Version(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info["revision"] = setting.Value
+			case "vcs.time":
+				info["revision_time"] = setting.Value
+			case "vcs.modified":
+				info["dirty"] = setting.Value
+			}
+		}
+	}
+	return info
+}
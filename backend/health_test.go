@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProbeIgnoresCallerCancellation(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	probe := newDependencyProbe(time.Minute, func(ctx context.Context) error {
+		calls.Add(1)
+		<-release
+		return ctx.Err()
+	})
+
+	// The caller gives up while the check is still running
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := probe.result(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the caller's cancellation, got %v", err)
+	}
+	close(release)
+
+	// The check itself completes unaffected and its result is cached
+	if _, err := probe.result(context.Background()); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if _, err := probe.result(context.Background()); err != nil || calls.Load() != 1 {
+		t.Fatalf("expected one cached check, got %d calls and %v", calls.Load(), err)
+	}
+}
+
+func TestProbeSharesOneCheck(t *testing.T) {
+	var calls atomic.Int32
+	down := errors.New("connection refused")
+	probe := newDependencyProbe(time.Minute, func(ctx context.Context) error {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return down
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := probe.result(context.Background()); !errors.Is(err, down) {
+				t.Errorf("expected the probe error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("expected one check for concurrent callers, got %d", calls.Load())
+	}
+}
+
+func TestProbeRechecksAfterTTL(t *testing.T) {
+	var calls atomic.Int32
+	probe := newDependencyProbe(10*time.Millisecond, func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	probe.result(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	probe.result(context.Background())
+	if calls.Load() != 2 {
+		t.Fatalf("expected a fresh check after the TTL, got %d checks", calls.Load())
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"binge-base/models"
+	"binge-base/services"
+)
+
+// Locale middleware. Picks the language from ?lang=, then Accept-Language,
+// and the region from ?region=, then the language tag, so "es-MX" alone
+// selects Mexican Spanish and Mexican release dates. TMDB calls made while
+// handling the request use this locale.
+func (s *Server) localeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := services.Locale{Language: services.DefaultLanguage}
+		if lang := r.URL.Query().Get("lang"); lang != "" {
+			tag, ok := parseLanguageTag(lang)
+			if !ok {
+				s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "lang must be a language code such as fr or es-MX")
+				return
+			}
+			locale = tag
+		} else if tag, ok := preferredLanguage(r.Header.Get("Accept-Language")); ok {
+			locale = tag
+		}
+		if region := r.URL.Query().Get("region"); region != "" {
+			if !isLetters(region, 2) {
+				s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "region must be a two-letter country code such as FR")
+				return
+			}
+			locale.Region = strings.ToUpper(region)
+		}
+
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", locale.Language)
+		next.ServeHTTP(w, r.WithContext(services.WithLocale(r.Context(), locale)))
+	})
+}
+
+// parseLanguageTag accepts "fr", "es-MX" or "pt_br", ignoring script and
+// variant subtags TMDB doesn't understand ("zh-Hant-TW" becomes "zh-TW")
+func parseLanguageTag(tag string) (services.Locale, bool) {
+	parts := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || !isLetters(parts[0], 2) {
+		return services.Locale{}, false
+	}
+	locale := services.Locale{Language: strings.ToLower(parts[0])}
+	for _, part := range parts[1:] {
+		if isLetters(part, 2) {
+			locale.Region = strings.ToUpper(part)
+			locale.Language += "-" + locale.Region
+			break
+		}
+	}
+	return locale, true
+}
+
+// preferredLanguage returns the highest-weighted usable language of an
+// Accept-Language header
+func preferredLanguage(header string) (services.Locale, bool) {
+	type candidate struct {
+		locale services.Locale
+		weight float64
+	}
+	var candidates []candidate
+	for _, entry := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if locale, ok := parseLanguageTag(tag); ok && weight > 0 {
+			candidates = append(candidates, candidate{locale, weight})
+		}
+	}
+	if len(candidates) == 0 {
+		return services.Locale{}, false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].weight > candidates[j].weight })
+	return candidates[0].locale, true
+}
+
+func isLetters(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	requestInfoKey
+)
+
+// RequestInfo carries per-request details that are only known once a
+// handler has run, such as the user a watchlist request acted on
+type RequestInfo struct {
+	mu   sync.Mutex
+	user string
+}
+
+// Setup installs a JSON logger on stdout as the process-wide default, so
+// the standard log package is routed through it as well
+func Setup(level string) {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn", "warning":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: lvl})))
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID stored in the context, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithRequestInfo returns a context carrying a fresh RequestInfo
+func WithRequestInfo(ctx context.Context) (context.Context, *RequestInfo) {
+	info := &RequestInfo{}
+	return context.WithValue(ctx, requestInfoKey, info), info
+}
+
+// SetUser records the user a request acted on for the access log
+func SetUser(ctx context.Context, user string) {
+	if info, ok := ctx.Value(requestInfoKey).(*RequestInfo); ok {
+		info.mu.Lock()
+		info.user = user
+		info.mu.Unlock()
+	}
+}
+
+// User returns the user recorded for the request
+func (i *RequestInfo) User() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.user
+}
+
+// FromContext returns the default logger annotated with the request ID
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
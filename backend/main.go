@@ -1,588 +1,620 @@
-package main
-
-import (
-	"encoding/json"
-	"log"
-	"net/http"
-	"strconv"
-	"strings"
-
-	"binge-base/config"
-	"binge-base/database"
-	"binge-base/services"
-
-	"github.com/joho/godotenv"
-)
-
-type Server struct {
-	config      *config.Config
-	db          *database.Database
-	tmdbService *services.TMDBService
-}
-
-func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
-	}
-
-	// Load configuration
-	cfg := config.Load()
-
-	// Initialize database
-	db, err := database.NewDatabase(cfg.DBPath)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-	defer db.Close()
-
-	// Initialize TMDB service
-	tmdbService := services.NewTMDBService(cfg)
-
-	// Create server instance
-	server := &Server{
-		config:      cfg,
-		db:          db,
-		tmdbService: tmdbService,
-	}
-
-	// Set up routes
-	mux := http.NewServeMux()
-
-	// API routes
-	mux.HandleFunc("/api/v1/health", server.healthHandler)
-	mux.HandleFunc("/api/v1/search", server.searchHandler)
-	mux.HandleFunc("/api/v1/search/movies", server.searchMoviesHandler)
-	mux.HandleFunc("/api/v1/search/tv", server.searchTVHandler)
-	mux.HandleFunc("/api/v1/movie/", server.movieDetailsHandler)
-	mux.HandleFunc("/api/v1/tv/", server.tvDetailsHandler)
-	mux.HandleFunc("/api/v1/trending", server.trendingHandler)
-	mux.HandleFunc("/api/v1/trending/movies", server.trendingMoviesHandler)
-	mux.HandleFunc("/api/v1/trending/tv", server.trendingTVHandler)
-	mux.HandleFunc("/api/v1/watchlist", server.watchlistHandler)
-	mux.HandleFunc("/api/v1/genres", server.genresHandler)
-	mux.HandleFunc("/api/v1/genres/", server.genresContentHandler)
-
-	// Apply CORS middleware
-	handler := corsMiddleware(mux)
-
-	// Get port from environment or use default
-	port := cfg.Port
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("🚀 BingeBase API server starting on port %s", port)
-	log.Printf("📊 Database: %s", cfg.DBPath)
-	if err := http.ListenAndServe(":"+port, handler); err != nil {
-		log.Fatal("Failed to start server:", err)
-	}
-}
-
-// CORS middleware
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Set content type for JSON responses
-		w.Header().Set("Content-Type", "application/json")
-
-		next.ServeHTTP(w, r)
-	})
-}
-
-// Helper function to send JSON responses
-func (s *Server) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("Error encoding JSON response: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-	}
-}
-
-// Helper function to send error responses
-func (s *Server) sendError(w http.ResponseWriter, statusCode int, message string) {
-	s.sendJSON(w, statusCode, map[string]interface{}{
-		"success": false,
-		"error":   message,
-	})
-}
-
-// Health check handler
-func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	s.sendJSON(w, http.StatusOK, map[string]interface{}{
-		"status":   "ok",
-		"message":  "BingeBase API is running",
-		"database": "connected",
-	})
-}
-
-// Search handlers
-func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	query := r.URL.Query().Get("query")
-	if query == "" {
-		s.sendError(w, http.StatusBadRequest, "Query parameter is required")
-		return
-	}
-	// We'll fetch the first 3 pages for more results
-	maxPages := 3
-	var allMovieResults, allTVResults []interface{}
-	totalMovieResults := 0
-	totalTVResults := 0
-	totalMoviePages := 0
-	totalTVPages := 0
-	for page := 1; page <= maxPages; page++ {
-		movieResults, err := s.tmdbService.SearchMovies(query, page)
-		if err == nil && movieResults != nil {
-			for _, m := range movieResults.Results {
-				if movie, ok := m.(map[string]interface{}); ok {
-					movie["media_type"] = "movie"
-				}
-				allMovieResults = append(allMovieResults, m)
-			}
-			totalMovieResults = movieResults.TotalResults
-			if movieResults.TotalPages > totalMoviePages {
-				totalMoviePages = movieResults.TotalPages
-			}
-		}
-		tvResults, err := s.tmdbService.SearchTVShows(query, page)
-		if err == nil && tvResults != nil {
-			for _, t := range tvResults.Results {
-				if tv, ok := t.(map[string]interface{}); ok {
-					tv["media_type"] = "tv"
-				}
-				allTVResults = append(allTVResults, t)
-			}
-			totalTVResults = tvResults.TotalResults
-			if tvResults.TotalPages > totalTVPages {
-				totalTVPages = tvResults.TotalPages
-			}
-		}
-	}
-	results := append(allMovieResults, allTVResults...)
-	totalResults := totalMovieResults + totalTVResults
-	totalPages := totalMoviePages
-	if totalTVPages > totalPages {
-		totalPages = totalTVPages
-	}
-	resp := map[string]interface{}{
-		"success":       true,
-		"page":          1,
-		"results":       results,
-		"total_pages":   totalPages,
-		"total_results": totalResults,
-	}
-	s.sendJSON(w, http.StatusOK, resp)
-}
-
-func (s *Server) searchMoviesHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	query := r.URL.Query().Get("query")
-	if query == "" {
-		s.sendError(w, http.StatusBadRequest, "Query parameter is required")
-		return
-	}
-	maxPages := 3
-	var allMovieResults []interface{}
-	totalMovieResults := 0
-	totalMoviePages := 0
-	for page := 1; page <= maxPages; page++ {
-		movieResults, err := s.tmdbService.SearchMovies(query, page)
-		if err == nil && movieResults != nil {
-			for _, m := range movieResults.Results {
-				if movie, ok := m.(map[string]interface{}); ok {
-					movie["media_type"] = "movie"
-				}
-				allMovieResults = append(allMovieResults, m)
-			}
-			totalMovieResults = movieResults.TotalResults
-			if movieResults.TotalPages > totalMoviePages {
-				totalMoviePages = movieResults.TotalPages
-			}
-		}
-	}
-	resp := map[string]interface{}{
-		"success":       true,
-		"page":          1,
-		"results":       allMovieResults,
-		"total_pages":   totalMoviePages,
-		"total_results": totalMovieResults,
-	}
-	s.sendJSON(w, http.StatusOK, resp)
-}
-
-func (s *Server) searchTVHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	query := r.URL.Query().Get("query")
-	if query == "" {
-		s.sendError(w, http.StatusBadRequest, "Query parameter is required")
-		return
-	}
-	maxPages := 3
-	var allTVResults []interface{}
-	totalTVResults := 0
-	totalTVPages := 0
-	for page := 1; page <= maxPages; page++ {
-		tvResults, err := s.tmdbService.SearchTVShows(query, page)
-		if err == nil && tvResults != nil {
-			for _, t := range tvResults.Results {
-				if tv, ok := t.(map[string]interface{}); ok {
-					tv["media_type"] = "tv"
-				}
-				allTVResults = append(allTVResults, t)
-			}
-			totalTVResults = tvResults.TotalResults
-			if tvResults.TotalPages > totalTVPages {
-				totalTVPages = tvResults.TotalPages
-			}
-		}
-	}
-	resp := map[string]interface{}{
-		"success":       true,
-		"page":          1,
-		"results":       allTVResults,
-		"total_pages":   totalTVPages,
-		"total_results": totalTVResults,
-	}
-	s.sendJSON(w, http.StatusOK, resp)
-}
-
-// Movie details handler
-func (s *Server) movieDetailsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	// Extract movie ID from URL path (last part)
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 2 {
-		s.sendError(w, http.StatusBadRequest, "Invalid movie ID")
-		return
-	}
-	movieIDStr := pathParts[len(pathParts)-1]
-	movieID, err := strconv.Atoi(movieIDStr)
-	if err != nil {
-		s.sendError(w, http.StatusBadRequest, "Invalid movie ID")
-		return
-	}
-
-	movie, err := s.tmdbService.GetMovieDetails(movieID)
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch movie details: "+err.Error())
-		return
-	}
-
-	// Optionally: fetch OMDB ratings if IMDB id is available
-	// (You can add OMDBService to Server struct if you want to fetch more ratings)
-
-	s.sendJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"data":    movie,
-	})
-}
-
-// TV details handler
-func (s *Server) tvDetailsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	// Extract TV show ID from URL path (last part)
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 2 {
-		s.sendError(w, http.StatusBadRequest, "Invalid TV show ID")
-		return
-	}
-	tvIDStr := pathParts[len(pathParts)-1]
-	tvID, err := strconv.Atoi(tvIDStr)
-	if err != nil {
-		s.sendError(w, http.StatusBadRequest, "Invalid TV show ID")
-		return
-	}
-	tvShow, err := s.tmdbService.GetTVDetails(tvID)
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch TV show details: "+err.Error())
-		return
-	}
-
-	s.sendJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"data":    tvShow,
-	})
-}
-
-// Trending handlers
-func (s *Server) trendingHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-	pageStr := r.URL.Query().Get("page")
-	page := 1
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	movies, err := s.tmdbService.GetTrendingMovies(page)
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch trending movies: "+err.Error())
-		return
-	}
-	tv, err := s.tmdbService.GetTrendingTVShows(page)
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch trending TV shows: "+err.Error())
-		return
-	}
-	results := append(movies.Results, tv.Results...)
-	totalResults := movies.TotalResults + tv.TotalResults
-	totalPages := movies.TotalPages
-	if tv.TotalPages > totalPages {
-		totalPages = tv.TotalPages
-	}
-	resp := map[string]interface{}{
-		"success":       true,
-		"page":          page,
-		"results":       results,
-		"total_pages":   totalPages,
-		"total_results": totalResults,
-	}
-	s.sendJSON(w, http.StatusOK, resp)
-}
-
-func (s *Server) trendingMoviesHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-	pageStr := r.URL.Query().Get("page")
-	page := 1
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	movies, err := s.tmdbService.GetTrendingMovies(page)
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch trending movies: "+err.Error())
-		return
-	}
-	resp := map[string]interface{}{
-		"success":       true,
-		"page":          page,
-		"results":       movies.Results,
-		"total_pages":   movies.TotalPages,
-		"total_results": movies.TotalResults,
-	}
-	s.sendJSON(w, http.StatusOK, resp)
-}
-
-func (s *Server) trendingTVHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-	pageStr := r.URL.Query().Get("page")
-	page := 1
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	tv, err := s.tmdbService.GetTrendingTVShows(page)
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch trending TV shows: "+err.Error())
-		return
-	}
-	resp := map[string]interface{}{
-		"success":       true,
-		"page":          page,
-		"results":       tv.Results,
-		"total_pages":   tv.TotalPages,
-		"total_results": tv.TotalResults,
-	}
-	s.sendJSON(w, http.StatusOK, resp)
-}
-
-// Watchlist handler
-func (s *Server) watchlistHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		userID := r.URL.Query().Get("user_id")
-		if userID == "" {
-			userID = "default_user"
-		}
-		items, err := s.db.GetWatchlist(userID)
-		if err != nil {
-			s.sendError(w, http.StatusInternalServerError, "Failed to get watchlist")
-			return
-		}
-		// Fetch real details for each item
-		var detailedItems []map[string]interface{}
-		for _, item := range items {
-			wi, ok := item.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			contentID, _ := wi["content_id"].(int)
-			contentType, _ := wi["content_type"].(string)
-			isWatched, _ := wi["is_watched"].(bool)
-			var details interface{}
-			if contentType == "movie" {
-				details, _ = s.tmdbService.GetMovieDetails(contentID)
-			} else if contentType == "tv" {
-				details, _ = s.tmdbService.GetTVDetails(contentID)
-			}
-			entry := map[string]interface{}{
-				"contentId":   contentID,
-				"contentType": contentType,
-				"isWatched":   isWatched,
-				"details":     details,
-			}
-			detailedItems = append(detailedItems, entry)
-		}
-		s.sendJSON(w, http.StatusOK, map[string]interface{}{
-			"success": true,
-			"data":    detailedItems,
-		})
-
-	case http.MethodPost:
-		var request struct {
-			UserID      string `json:"user_id"`
-			ContentID   int    `json:"content_id"`
-			ContentType string `json:"content_type"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			s.sendError(w, http.StatusBadRequest, "Invalid request body")
-			return
-		}
-		if request.UserID == "" {
-			request.UserID = "default_user"
-		}
-		if err := s.db.AddToWatchlist(request.UserID, request.ContentID, request.ContentType); err != nil {
-			s.sendError(w, http.StatusInternalServerError, "Failed to add to watchlist")
-			return
-		}
-		s.sendJSON(w, http.StatusOK, map[string]interface{}{
-			"success": true,
-			"message": "Added to watchlist",
-		})
-
-	case http.MethodDelete:
-		userID := r.URL.Query().Get("user_id")
-		if userID == "" {
-			userID = "default_user"
-		}
-		contentIDStr := r.URL.Query().Get("content_id")
-		contentType := r.URL.Query().Get("content_type")
-		contentID, err := strconv.Atoi(contentIDStr)
-		if err != nil {
-			s.sendError(w, http.StatusBadRequest, "Invalid content ID")
-			return
-		}
-		if err := s.db.RemoveFromWatchlist(userID, contentID, contentType); err != nil {
-			s.sendError(w, http.StatusInternalServerError, "Failed to remove from watchlist")
-			return
-		}
-		s.sendJSON(w, http.StatusOK, map[string]interface{}{
-			"success": true,
-			"message": "Removed from watchlist",
-		})
-
-	case http.MethodPut:
-		var request struct {
-			UserID      string `json:"user_id"`
-			ContentID   int    `json:"content_id"`
-			ContentType string `json:"content_type"`
-			IsWatched   bool   `json:"is_watched"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			s.sendError(w, http.StatusBadRequest, "Invalid request body")
-			return
-		}
-		if request.UserID == "" {
-			request.UserID = "default_user"
-		}
-		if err := s.db.MarkAsWatched(request.UserID, request.ContentID, request.ContentType, request.IsWatched); err != nil {
-			s.sendError(w, http.StatusInternalServerError, "Failed to update watched status")
-			return
-		}
-		s.sendJSON(w, http.StatusOK, map[string]interface{}{
-			"success": true,
-			"message": "Watch status updated",
-		})
-
-	default:
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-	}
-}
-
-// Genres handlers
-func (s *Server) genresHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	genres, err := s.tmdbService.GetGenres()
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to fetch genres: "+err.Error())
-		return
-	}
-
-	s.sendJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"data":    genres,
-	})
-}
-
-func (s *Server) genresContentHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		s.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	// Extract genre ID and content type from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		s.sendError(w, http.StatusBadRequest, "Invalid genre path")
-		return
-	}
-	genreID := pathParts[3]
-	contentType := pathParts[4]
-
-	s.sendJSON(w, http.StatusOK, map[string]interface{}{
-		"message":      "Get content by genre endpoint - to be implemented",
-		"genre_id":     genreID,
-		"content_type": contentType,
-	})
-}
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"binge-base/config"
+	"binge-base/database"
+	"binge-base/logging"
+	"binge-base/metrics"
+	"binge-base/models"
+	"binge-base/openapi"
+	"binge-base/router"
+	"binge-base/services"
+	"binge-base/web"
+
+	"github.com/joho/godotenv"
+)
+
+type Server struct {
+	config         *config.Config
+	db             *database.Database
+	tmdbService    *services.TMDBService
+	omdbService    *services.OMDBService
+	webhookService *services.WebhookService
+	followWatcher  *services.FollowWatcher
+	tmdbProbe      *dependencyProbe
+	omdbProbe      *dependencyProbe
+	clientLimits   *clientLimiter
+	shuttingDown   atomic.Bool
+}
+
+func main() {
+	// Load environment variables
+	envErr := godotenv.Load()
+
+	// Load configuration
+	cfg := config.Load()
+	logging.Setup(cfg.LogLevel)
+	if envErr != nil {
+		slog.Info("no .env file found, using system environment variables")
+	}
+
+	// Initialize database
+	db, err := database.NewDatabase(cfg.DBPath)
+	if err != nil {
+		slog.Error("failed to initialize database", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize TMDB and OMDB services
+	tmdbService := services.NewTMDBService(cfg)
+	omdbService := services.NewOMDBService(cfg)
+
+	// Start webhook delivery workers, the upcoming-release scanner, the
+	// genre catalog refresh and the scan for titles from followed people
+	webhookService := services.NewWebhookService(cfg, db)
+	webhookService.Start(4)
+
+	releaseNotifier := services.NewReleaseNotifier(db, tmdbService, webhookService, cfg.ReleaseNoticeDays)
+	releaseNotifier.Start(6 * time.Hour)
+
+	genreRefresher := services.NewGenreRefresher(db, tmdbService)
+	genreRefresher.Start(24 * time.Hour)
+
+	followWatcher := services.NewFollowWatcher(db, tmdbService)
+	followWatcher.Start(12 * time.Hour)
+
+	// Every OMDB probe spends a call from its small daily quota
+	var omdbProbe *dependencyProbe
+	if cfg.ProbeOMDB {
+		omdbProbe = newDependencyProbe(time.Duration(cfg.HealthProbeTTL)*time.Second, omdbService.Ping)
+	}
+
+	// Create server instance
+	server := &Server{
+		config:         cfg,
+		db:             db,
+		tmdbService:    tmdbService,
+		omdbService:    omdbService,
+		webhookService: webhookService,
+		followWatcher:  followWatcher,
+		tmdbProbe:      newDependencyProbe(time.Duration(cfg.HealthProbeTTL)*time.Second, tmdbService.Ping),
+		omdbProbe:      omdbProbe,
+		clientLimits:   newClientLimiter(cfg),
+	}
+
+	// API documentation. Every registered route must be described in the
+	// spec; openapi_test.go checks it.
+	specJSON, err := json.Marshal(apiSpec())
+	if err != nil {
+		slog.Error("failed to encode OpenAPI spec", "error", err)
+		os.Exit(1)
+	}
+	rt := server.routes(specJSON)
+
+	// Apply middleware; request logging wraps everything so preflights
+	// and 404s are logged too. The embedded frontend sits outside the JSON
+	// middleware and takes every GET the API doesn't own.
+	var handler http.Handler = corsMiddleware(cfg)(rt)
+	if assets, ok := web.Assets(); ok && cfg.ServeFrontend {
+		handler = web.WithFrontend(handler, assets, "/api/", "/feeds/", "/metrics")
+		slog.Info("serving embedded frontend")
+	}
+	handler = requestLogMiddleware(compressMiddleware(handler))
+
+	// Get port from environment or use default
+	port := cfg.Port
+	if port == "" {
+		port = "8080"
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadTimeout:       time.Duration(cfg.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.IdleTimeout) * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("BingeBase API server starting", "port", port, "database", cfg.DBPath)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serveErr:
+		slog.Error("failed to start server", "error", err)
+		exitCode = 1
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, draining connections", "timeout_seconds", cfg.ShutdownTimeout)
+	}
+	stop()
+
+	// Fail readiness first and keep serving for the drain delay, so the
+	// platform sees it and stops routing new traffic here. Then drain
+	// in-flight requests before stopping background work and closing the
+	// database they depend on. The delay counts toward the timeout.
+	server.shuttingDown.Store(true)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout)*time.Second)
+	if exitCode == 0 && cfg.DrainDelay > 0 {
+		slog.Info("failing readiness before closing the listener", "delay_seconds", cfg.DrainDelay)
+		select {
+		case <-time.After(time.Duration(cfg.DrainDelay) * time.Second):
+		case <-shutdownCtx.Done():
+		}
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to drain connections before deadline", "error", err)
+		exitCode = 1
+	}
+	cancel()
+
+	releaseNotifier.Stop()
+	genreRefresher.Stop()
+	followWatcher.Stop()
+	webhookService.Stop()
+	if err := db.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+		exitCode = 1
+	}
+
+	slog.Info("server stopped")
+	os.Exit(exitCode)
+}
+
+// routes registers every endpoint on a new router. specJSON is served as
+// the OpenAPI document.
+func (s *Server) routes(specJSON []byte) *router.Router {
+	rt := router.New()
+	rt.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.sendError(w, http.StatusNotFound, models.ErrCodeNotFound, "Not found")
+	})
+	rt.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.sendError(w, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "Method not allowed")
+	})
+	rt.Use(metricsMiddleware, s.rateLimitMiddleware, s.localeMiddleware, cacheMiddleware)
+	rt.Handle(http.MethodGet, "/metrics", s.metricsAuth(s.config.MetricsToken, metrics.Handler()))
+
+	// API routes
+	api := rt.Group("/api/v1")
+	api.Get("/health", s.readinessHandler)
+	api.Get("/health/live", s.livenessHandler)
+	api.Get("/health/ready", s.readinessHandler)
+	api.Get("/search", s.searchHandler)
+	api.Get("/search/movies", s.searchMoviesHandler)
+	api.Get("/search/tv", s.searchTVHandler)
+	api.Get("/search/people", s.searchPeopleHandler)
+	api.Get("/trending", s.trendingHandler)
+	api.Get("/trending/movies", s.trendingMoviesHandler)
+	api.Get("/trending/tv", s.trendingTVHandler)
+	api.Get("/genres", s.genresHandler)
+	api.Get("/genres/{id}/{type}", s.genresContentHandler)
+
+	movie := api.Group("/movie/{id}")
+	movie.Get("", s.movieDetailsHandler)
+	movie.Get("/providers", s.movieProvidersHandler)
+
+	tv := api.Group("/tv/{id}")
+	tv.Get("", s.tvDetailsHandler)
+	tv.Get("/season/{season}", s.seasonHandler)
+	tv.Get("/season/{season}/episode/{episode}", s.episodeHandler)
+
+	api.Get("/collection/{id}", s.collectionHandler)
+	api.Get("/person/{id}", s.personHandler)
+
+	api.Get("/settings", s.getSettingsHandler)
+	api.Put("/settings", s.updateSettingsHandler)
+
+	api.Get("/watchlist", s.getWatchlistHandler)
+	api.Post("/watchlist", s.addToWatchlistHandler)
+	api.Put("/watchlist", s.updateWatchlistHandler)
+	api.Delete("/watchlist", s.removeFromWatchlistHandler)
+	api.Put("/watchlist/episodes", s.updateWatchedEpisodeHandler)
+	api.Post("/watchlist/collection", s.addCollectionToWatchlistHandler)
+
+	api.Get("/follows", s.getFollowsHandler)
+	api.Post("/follows", s.createFollowHandler)
+	api.Delete("/follows", s.deleteFollowHandler)
+	api.Get("/follows/releases", s.followedReleasesHandler)
+
+	api.Get("/webhooks", s.getWebhooksHandler)
+	api.Post("/webhooks", s.createWebhookHandler)
+	api.Delete("/webhooks", s.deleteWebhookHandler)
+	api.Get("/webhooks/deliveries", s.webhookDeliveriesHandler)
+
+	// Feeds
+	rt.Get("/feeds/trending.atom", s.trendingFeedHandler)
+	rt.Get("/feeds/activity.atom", s.activityFeedHandler)
+	rt.Get("/feeds/following.atom", s.followingFeedHandler)
+
+	// API documentation
+	rt.Get(openAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write(specJSON)
+	})
+	rt.Handle(http.MethodGet, apiDocsPath, openapi.DocsHandler(openAPIPath, apiDocsPath+"/"))
+	rt.Handle(http.MethodGet, apiDocsPath+"/{file}", openapi.AssetsHandler())
+	return rt
+}
+
+// Helper function to send JSON responses. Successful responses carry a
+// strong ETag over the body, which cacheMiddleware turns into a 304 when
+// the client already has it.
+func (s *Server) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	if statusCode == http.StatusOK {
+		sum := sha256.Sum256(body)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// sendData answers 200 with data in the standard envelope
+func (s *Server) sendData(w http.ResponseWriter, data interface{}) {
+	s.sendJSON(w, http.StatusOK, models.APIResponse{Success: true, Data: data})
+}
+
+// sendPage answers 200 with one page of a list
+func (s *Server) sendPage(w http.ResponseWriter, data interface{}, pagination models.Pagination) {
+	s.sendJSON(w, http.StatusOK, models.APIResponse{Success: true, Data: data, Pagination: &pagination})
+}
+
+// sendMessage answers 200 with a confirmation message
+func (s *Server) sendMessage(w http.ResponseWriter, message string) {
+	s.sendJSON(w, http.StatusOK, models.APIResponse{Success: true, Message: message})
+}
+
+// Helper function to send error responses
+func (s *Server) sendError(w http.ResponseWriter, statusCode int, code, message string) {
+	s.sendJSON(w, statusCode, models.APIResponse{Success: false, Error: message, Code: code})
+}
+
+// sendUpstreamError answers for a failed TMDB or OMDB call. Resources the
+// upstream API doesn't know become our 404; anything else is a 502, with
+// the underlying error logged rather than shown to the client.
+func (s *Server) sendUpstreamError(w http.ResponseWriter, r *http.Request, err error, notFound, failed string) {
+	if errors.Is(err, services.ErrNotFound) && notFound != "" {
+		s.sendError(w, http.StatusNotFound, models.ErrCodeNotFound, notFound)
+		return
+	}
+	logging.FromContext(r.Context()).Warn("upstream request failed", "error", err)
+	s.sendError(w, http.StatusBadGateway, models.ErrCodeUpstreamUnavailable, failed)
+}
+
+// decodeJSON reads a size-limited JSON request body into dst. It answers
+// with 400 or 413 itself and returns false when the body is unusable.
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.sendError(w, http.StatusRequestEntityTooLarge, models.ErrCodePayloadTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", tooLarge.Limit))
+			return false
+		}
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+// pageParam reads the optional 1-based page query parameter
+func pageParam(r *http.Request) int {
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		return p
+	}
+	return 1
+}
+
+// Search handlers
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	s.searchMedia(w, r, "movie", "tv", "person")
+}
+
+func (s *Server) searchMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	s.searchMedia(w, r, "movie")
+}
+
+func (s *Server) searchTVHandler(w http.ResponseWriter, r *http.Request) {
+	s.searchMedia(w, r, "tv")
+}
+
+func (s *Server) searchPeopleHandler(w http.ResponseWriter, r *http.Request) {
+	s.searchMedia(w, r, "person")
+}
+
+// sendMergedPage answers with the requested page of the merged sources
+func (s *Server) sendMergedPage(w http.ResponseWriter, r *http.Request, sources []resultSource, failed string) {
+	results, pagination, err := mergedPage(r.Context(), sources, pageParam(r), perPageParam(r))
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "", failed)
+		return
+	}
+	s.sendPage(w, parseFields(r).items(results), pagination)
+}
+
+// Movie details handler
+func (s *Server) movieDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid movie ID")
+		return
+	}
+
+	movie, err := s.tmdbService.GetMovieDetails(r.Context(), movieID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Movie not found", "Failed to fetch movie details")
+		return
+	}
+
+	// Optionally: fetch OMDB ratings if IMDB id is available
+	// (You can add OMDBService to Server struct if you want to fetch more ratings)
+
+	s.sendData(w, movie)
+}
+
+// Movie providers handler
+func (s *Server) movieProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid movie ID")
+		return
+	}
+
+	providers, err := s.tmdbService.GetMovieProviders(r.Context(), movieID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Movie not found", "Failed to fetch movie providers")
+		return
+	}
+
+	s.sendData(w, providers["results"])
+}
+
+// TV details handler
+func (s *Server) tvDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	tvID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid TV show ID")
+		return
+	}
+	tvShow, err := s.tmdbService.GetTVDetails(r.Context(), tvID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "TV show not found", "Failed to fetch TV show details")
+		return
+	}
+
+	s.sendData(w, tvShow)
+}
+
+// Trending handlers
+func (s *Server) trendingHandler(w http.ResponseWriter, r *http.Request) {
+	s.sendMergedPage(w, r, []resultSource{s.trendingSource("movie"), s.trendingSource("tv")}, "Failed to fetch trending titles")
+}
+
+func (s *Server) trendingMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	s.sendMergedPage(w, r, []resultSource{s.trendingSource("movie")}, "Failed to fetch trending movies")
+}
+
+func (s *Server) trendingTVHandler(w http.ResponseWriter, r *http.Request) {
+	s.sendMergedPage(w, r, []resultSource{s.trendingSource("tv")}, "Failed to fetch trending TV shows")
+}
+
+func (s *Server) trendingSource(mediaType string) resultSource {
+	return resultSource{mediaType: mediaType, fetch: func(ctx context.Context, page int) (*models.SearchResult, error) {
+		var result *models.TrendingResult
+		var err error
+		if mediaType == "movie" {
+			result, err = s.tmdbService.GetTrendingMovies(ctx, page)
+		} else {
+			result, err = s.tmdbService.GetTrendingTVShows(ctx, page)
+		}
+		return (*models.SearchResult)(result), err
+	}}
+}
+
+// Watchlist handlers
+func (s *Server) getWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	items, err := s.db.GetWatchlist(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get watchlist")
+		return
+	}
+	// Fetch real details for each item
+	fields := parseFields(r)
+	entries := []models.WatchlistEntry{}
+	for _, item := range items {
+		wi, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry := models.WatchlistEntry{}
+		entry.ContentID, _ = wi["content_id"].(int)
+		entry.ContentType, _ = wi["content_type"].(string)
+		entry.IsWatched, _ = wi["is_watched"].(bool)
+		if entry.ContentType == "movie" {
+			if movie, err := s.tmdbService.GetMovieDetails(r.Context(), entry.ContentID); err == nil {
+				entry.Details = fields.details(movie, movieSummary(movie))
+			}
+		} else if entry.ContentType == "tv" {
+			if show, err := s.tmdbService.GetTVDetails(r.Context(), entry.ContentID); err == nil {
+				entry.Details = fields.details(show, tvSummary(show))
+			}
+		}
+		entries = append(entries, entry)
+	}
+	s.sendData(w, entries)
+}
+
+type addWatchlistRequest struct {
+	UserID      string `json:"user_id"`
+	ContentID   int    `json:"content_id"`
+	ContentType string `json:"content_type"`
+}
+
+func (s *Server) addToWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var request addWatchlistRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	if err := s.db.AddToWatchlist(request.UserID, request.ContentID, request.ContentType); err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to add to watchlist")
+		return
+	}
+	s.webhookService.Dispatch(request.UserID, services.EventWatchlistAdded, map[string]interface{}{
+		"content_id":   request.ContentID,
+		"content_type": request.ContentType,
+	})
+	s.sendMessage(w, "Added to watchlist")
+}
+
+func (s *Server) removeFromWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	contentIDStr := r.URL.Query().Get("content_id")
+	contentType := r.URL.Query().Get("content_type")
+	contentID, err := strconv.Atoi(contentIDStr)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid content ID")
+		return
+	}
+	if err := s.db.RemoveFromWatchlist(userID, contentID, contentType); err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to remove from watchlist")
+		return
+	}
+	s.webhookService.Dispatch(userID, services.EventWatchlistRemoved, map[string]interface{}{
+		"content_id":   contentID,
+		"content_type": contentType,
+	})
+	s.sendMessage(w, "Removed from watchlist")
+}
+
+type updateWatchlistRequest struct {
+	UserID      string `json:"user_id"`
+	ContentID   int    `json:"content_id"`
+	ContentType string `json:"content_type"`
+	IsWatched   bool   `json:"is_watched"`
+}
+
+func (s *Server) updateWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var request updateWatchlistRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	if err := s.db.MarkAsWatched(request.UserID, request.ContentID, request.ContentType, request.IsWatched); err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to update watched status")
+		return
+	}
+	event := services.EventWatchlistWatched
+	if !request.IsWatched {
+		event = services.EventWatchlistUnwatched
+	}
+	s.webhookService.Dispatch(request.UserID, event, map[string]interface{}{
+		"content_id":   request.ContentID,
+		"content_type": request.ContentType,
+		"is_watched":   request.IsWatched,
+	})
+	s.sendMessage(w, "Watch status updated")
+}
+
+// Genres handlers
+func (s *Server) genresHandler(w http.ResponseWriter, r *http.Request) {
+	mediaType := r.URL.Query().Get("type")
+	if mediaType != "" && mediaType != "movie" && mediaType != "tv" {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Type must be movie or tv")
+		return
+	}
+
+	genres, err := s.genreCatalog(r.Context())
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "", "Failed to fetch genres")
+		return
+	}
+	if mediaType != "" {
+		filtered := []models.Genre{}
+		for _, genre := range genres {
+			if genre.HasMediaType(mediaType) {
+				filtered = append(filtered, genre)
+			}
+		}
+		genres = filtered
+	}
+
+	s.sendData(w, genres)
+}
+
+// genreCatalog serves English from the stored catalog and other languages
+// from TMDB, falling back to the stored English names if TMDB fails. Until
+// the first refresh has stored anything, TMDB is always asked.
+func (s *Server) genreCatalog(ctx context.Context) ([]models.Genre, error) {
+	stored, dbErr := s.db.GetGenres()
+	if dbErr != nil {
+		logging.FromContext(ctx).Error("failed to read stored genres", "error", dbErr)
+	}
+	if len(stored) > 0 && services.LocaleFrom(ctx).English() {
+		return stored, nil
+	}
+	genres, err := s.tmdbService.GetGenres(ctx)
+	if err != nil && len(stored) > 0 {
+		logging.FromContext(ctx).Warn("serving stored genres, TMDB unavailable", "error", err)
+		return stored, nil
+	}
+	return genres, err
+}
+
+func (s *Server) genresContentHandler(w http.ResponseWriter, r *http.Request) {
+	genreID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid genre ID")
+		return
+	}
+	contentType := router.Param(r, "type")
+	if contentType != "movie" && contentType != "tv" {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Content type must be movie or tv")
+		return
+	}
+
+	s.sendMergedPage(w, r, []resultSource{{mediaType: contentType, fetch: func(ctx context.Context, page int) (*models.SearchResult, error) {
+		return s.tmdbService.DiscoverByGenre(ctx, contentType, genreID, page)
+	}}}, "Failed to fetch titles for genre")
+}
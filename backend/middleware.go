@@ -0,0 +1,256 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"binge-base/config"
+	"binge-base/logging"
+	"binge-base/metrics"
+	"binge-base/models"
+	"binge-base/router"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("bingebase_http_requests_total",
+		"HTTP requests served by method, route pattern and status code.", "method", "route", "status")
+	httpDuration = metrics.NewHistogramVec("bingebase_http_request_duration_seconds",
+		"Latency of HTTP requests by method and route pattern.", metrics.DefaultBuckets, "method", "route")
+)
+
+const (
+	corsAllowMethods  = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowHeaders  = "Content-Type, Authorization, X-Request-ID, Accept-Language"
+	corsExposeHeaders = "X-Request-ID, RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// Request logging middleware. Assigns each request an ID (or propagates a
+// well-formed one from the client), echoes it in the response and writes a
+// structured access log line once the handler returns.
+func requestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		ctx, info := logging.WithRequestInfo(ctx)
+		if userID := r.URL.Query().Get("user_id"); userID != "" {
+			logging.SetUser(ctx, userID)
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(ctx, level, "http_request",
+			slog.String("request_id", requestID),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("bytes", rec.bytes),
+			slog.String("user", info.User()),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		)
+	})
+}
+
+// Metrics middleware. Registered on the router so requests are labelled
+// with the route pattern rather than the raw path, keeping cardinality low.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := router.Pattern(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.Inc(r.Method, route, strconv.Itoa(rec.status))
+		httpDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
+
+// responseRecorder captures the status code and body size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps streaming responses working through the recorder
+func (rec *responseRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// metricsAuth serves next only to requests carrying the bearer token.
+// Without a configured token the endpoint is disabled, since the numbers
+// reveal routes, traffic and upstream quota use.
+func (s *Server) metricsAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			s.sendError(w, http.StatusNotFound, models.ErrCodeNotFound, "Metrics are disabled")
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			s.sendError(w, http.StatusUnauthorized, models.ErrCodeUnauthorized, "A valid metrics token is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CORS middleware. Only origins in cfg.AllowedOrigins are echoed back;
+// entries may be exact origins, "*.example.com" for any subdomain, or "*".
+// Origins admitted only by "*" get a literal "*" and never credentials, so a
+// wildcard can't open credentialed requests to every site.
+func corsMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+	allowAny := false
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+	}
+	maxAge := strconv.Itoa(cfg.CORSMaxAge)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			w.Header().Add("Vary", "Origin")
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			listed := origin != "" && originAllowed(origin, cfg.AllowedOrigins)
+			if listed || (origin != "" && allowAny) {
+				if listed {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					if cfg.CORSAllowCreds {
+						w.Header().Set("Access-Control-Allow-Credentials", "true")
+					}
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				}
+				w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+				if preflight {
+					w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+					w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+					w.Header().Set("Access-Control-Max-Age", maxAge)
+				}
+			}
+
+			// Handle preflight requests
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			// Set content type for JSON responses
+			w.Header().Set("Content-Type", "application/json")
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// originAllowed reports whether origin matches an allow-list entry. Wildcard
+// entries ("https://*.example.com" or "*.example.com") match subdomains only,
+// not the apex domain.
+func originAllowed(origin string, allowed []string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		if strings.EqualFold(entry, origin) {
+			return true
+		}
+
+		scheme, pattern := "", entry
+		if i := strings.Index(entry, "://"); i >= 0 {
+			scheme, pattern = entry[:i], entry[i+3:]
+		}
+		if !strings.HasPrefix(pattern, "*.") {
+			continue
+		}
+		if scheme != "" && !strings.EqualFold(scheme, u.Scheme) {
+			continue
+		}
+		suffix := strings.ToLower(pattern[1:])
+		host := strings.ToLower(u.Host)
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return true
+		}
+	}
+	return false
+}
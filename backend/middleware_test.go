@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"binge-base/config"
+)
+
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	listed := &config.Config{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.preview.example.com"},
+		CORSAllowCreds: true,
+		CORSMaxAge:     600,
+	}
+	wildcard := &config.Config{
+		AllowedOrigins: []string{"*", "https://app.example.com"},
+		CORSAllowCreds: true,
+		CORSMaxAge:     600,
+	}
+
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		method      string
+		origin      string
+		preflight   bool
+		status      int
+		allowOrigin string
+		credentials bool
+	}{
+		{"listed origin", listed, "GET", "https://app.example.com", false, http.StatusTeapot, "https://app.example.com", true},
+		{"subdomain pattern", listed, "GET", "https://pr-1.preview.example.com", false, http.StatusTeapot, "https://pr-1.preview.example.com", true},
+		{"pattern skips apex", listed, "GET", "https://preview.example.com", false, http.StatusTeapot, "", false},
+		{"unlisted origin", listed, "GET", "https://evil.example.net", false, http.StatusTeapot, "", false},
+		{"no origin", listed, "GET", "", false, http.StatusTeapot, "", false},
+		{"wildcard", wildcard, "GET", "https://evil.example.net", false, http.StatusTeapot, "*", false},
+		{"listed beside wildcard", wildcard, "GET", "https://app.example.com", false, http.StatusTeapot, "https://app.example.com", true},
+		{"preflight", listed, "OPTIONS", "https://app.example.com", true, http.StatusNoContent, "https://app.example.com", true},
+		{"unlisted preflight", listed, "OPTIONS", "https://evil.example.net", true, http.StatusNoContent, "", false},
+		{"wildcard preflight", wildcard, "OPTIONS", "https://evil.example.net", true, http.StatusNoContent, "*", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/api/v1/watchlist", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if tt.preflight {
+			r.Header.Set("Access-Control-Request-Method", "PUT")
+		}
+		w := httptest.NewRecorder()
+		corsMiddleware(tt.cfg)(next).ServeHTTP(w, r)
+		h := w.Header()
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if got := h.Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("%s: Allow-Origin %q, want %q", tt.name, got, tt.allowOrigin)
+		}
+		if got := h.Get("Access-Control-Allow-Credentials") == "true"; got != tt.credentials {
+			t.Errorf("%s: credentials %v, want %v", tt.name, got, tt.credentials)
+		}
+		allowed := tt.allowOrigin != ""
+		if got := h.Get("Access-Control-Allow-Methods") != ""; got != (allowed && tt.preflight) {
+			t.Errorf("%s: Allow-Methods present = %v", tt.name, got)
+		}
+		if tt.preflight && allowed && h.Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("%s: Max-Age %q", tt.name, h.Get("Access-Control-Max-Age"))
+		}
+		if h.Values("Vary")[0] != "Origin" {
+			t.Errorf("%s: Vary %v", tt.name, h.Values("Vary"))
+		}
+	}
+}
+
+func TestCORSCredentialsOff(t *testing.T) {
+	cfg := &config.Config{AllowedOrigins: []string{"https://app.example.com"}}
+	r := httptest.NewRequest("GET", "/api/v1/settings", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	corsMiddleware(cfg)(http.NotFoundHandler()).ServeHTTP(w, r)
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+}
+
+func TestMetricsAuth(t *testing.T) {
+	s := &Server{}
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("bingebase_up 1\n"))
+	})
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		status int
+	}{
+		{"disabled without a token", "", "Bearer ", http.StatusNotFound},
+		{"missing header", "s3cret", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret", "Bearer guess", http.StatusUnauthorized},
+		{"wrong scheme", "s3cret", "Basic s3cret", http.StatusUnauthorized},
+		{"valid token", "s3cret", "Bearer s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		s.metricsAuth(tt.token, metrics).ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if (w.Code == http.StatusOK) != (w.Body.String() == "bingebase_up 1\n") {
+			t.Errorf("%s: unexpected body %q", tt.name, w.Body.String())
+		}
+	}
+}
@@ -1,105 +1,577 @@
-package models
-
-import (
-	"time"
-)
-
-// Movie represents a movie from TMDB
-type Movie struct {
-	ID                   int                    `json:"id" db:"id"`
-	TMDBID               int                    `json:"tmdb_id" db:"tmdb_id"`
-	Title                string                 `json:"title" db:"title"`
-	Overview             string                 `json:"overview" db:"overview"`
-	PosterPath           string                 `json:"poster_path" db:"poster_path"`
-	BackdropPath         string                 `json:"backdrop_path" db:"backdrop_path"`
-	ReleaseDate          string                 `json:"release_date" db:"release_date"`
-	VoteAverage          float64                `json:"vote_average" db:"vote_average"`
-	VoteCount            int                    `json:"vote_count" db:"vote_count"`
-	Popularity           float64                `json:"popularity" db:"popularity"`
-	GenreIDs             []int                  `json:"genre_ids" db:"genre_ids"`
-	Runtime              int                    `json:"runtime" db:"runtime"`
-	Status               string                 `json:"status" db:"status"`
-	Tagline              string                 `json:"tagline" db:"tagline"`
-	Budget               int64                  `json:"budget" db:"budget"`
-	Revenue              int64                  `json:"revenue" db:"revenue"`
-	IMDBRating           string                 `json:"imdb_rating" db:"imdb_rating"`
-	RottenTomatoesRating string                 `json:"rotten_tomatoes_rating" db:"rotten_tomatoes_rating"`
-	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time              `json:"updated_at" db:"updated_at"`
-	Providers            interface{}            `json:"providers,omitempty"`
-	Videos               map[string]interface{} `json:"videos,omitempty"`
-	Trailer              string                 `json:"trailer,omitempty"`
-}
-
-// TVShow represents a TV show from TMDB
-type TVShow struct {
-	ID                   int       `json:"id" db:"id"`
-	TMDBID               int       `json:"tmdb_id" db:"tmdb_id"`
-	Name                 string    `json:"name" db:"name"`
-	Overview             string    `json:"overview" db:"overview"`
-	PosterPath           string    `json:"poster_path" db:"poster_path"`
-	BackdropPath         string    `json:"backdrop_path" db:"backdrop_path"`
-	FirstAirDate         string    `json:"first_air_date" db:"first_air_date"`
-	LastAirDate          string    `json:"last_air_date" db:"last_air_date"`
-	VoteAverage          float64   `json:"vote_average" db:"vote_average"`
-	VoteCount            int       `json:"vote_count" db:"vote_count"`
-	Popularity           float64   `json:"popularity" db:"popularity"`
-	GenreIDs             []int     `json:"genre_ids" db:"genre_ids"`
-	NumberOfSeasons      int       `json:"number_of_seasons" db:"number_of_seasons"`
-	NumberOfEpisodes     int       `json:"number_of_episodes" db:"number_of_episodes"`
-	Status               string    `json:"status" db:"status"`
-	Type                 string    `json:"type" db:"type"`
-	IMDBRating           string    `json:"imdb_rating" db:"imdb_rating"`
-	RottenTomatoesRating string    `json:"rotten_tomatoes_rating" db:"rotten_tomatoes_rating"`
-	CreatedAt            time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
-}
-
-// WatchlistItem represents an item in user's watchlist
-type WatchlistItem struct {
-	ID          int        `json:"id" db:"id"`
-	UserID      string     `json:"user_id" db:"user_id"`
-	ContentID   int        `json:"content_id" db:"content_id"`
-	ContentType string     `json:"content_type" db:"content_type"` // "movie" or "tv"
-	IsWatched   bool       `json:"is_watched" db:"is_watched"`
-	AddedAt     time.Time  `json:"added_at" db:"added_at"`
-	WatchedAt   *time.Time `json:"watched_at" db:"watched_at"`
-}
-
-// Genre represents a movie/TV show genre
-type Genre struct {
-	ID   int    `json:"id" db:"id"`
-	Name string `json:"name" db:"name"`
-}
-
-// SearchResult represents a search result
-type SearchResult struct {
-	Page         int           `json:"page"`
-	Results      []interface{} `json:"results"`
-	TotalPages   int           `json:"total_pages"`
-	TotalResults int           `json:"total_results"`
-}
-
-// TrendingResult represents trending content
-type TrendingResult struct {
-	Page         int           `json:"page"`
-	Results      []interface{} `json:"results"`
-	TotalPages   int           `json:"total_pages"`
-	TotalResults int           `json:"total_results"`
-}
-
-// APIResponse represents a generic API response
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
-}
-
-// Pagination represents pagination metadata
-type Pagination struct {
-	Page         int `json:"page"`
-	TotalPages   int `json:"total_pages"`
-	TotalResults int `json:"total_results"`
-	PerPage      int `json:"per_page"`
-}
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Movie represents a movie from TMDB
+type Movie struct {
+	ID                   int         `json:"id" db:"id"`
+	TMDBID               int         `json:"tmdb_id" db:"tmdb_id"`
+	Title                string      `json:"title" db:"title"`
+	Overview             string      `json:"overview" db:"overview"`
+	PosterPath           string      `json:"poster_path" db:"poster_path"`
+	BackdropPath         string      `json:"backdrop_path" db:"backdrop_path"`
+	ReleaseDate          string      `json:"release_date" db:"release_date"`
+	VoteAverage          float64     `json:"vote_average" db:"vote_average"`
+	VoteCount            int         `json:"vote_count" db:"vote_count"`
+	Popularity           float64     `json:"popularity" db:"popularity"`
+	GenreIDs             []int       `json:"genre_ids" db:"genre_ids"`
+	Runtime              int         `json:"runtime" db:"runtime"`
+	Status               string      `json:"status" db:"status"`
+	Tagline              string      `json:"tagline" db:"tagline"`
+	Budget               int64       `json:"budget" db:"budget"`
+	Revenue              int64       `json:"revenue" db:"revenue"`
+	IMDBRating           string      `json:"imdb_rating" db:"imdb_rating"`
+	RottenTomatoesRating string      `json:"rotten_tomatoes_rating" db:"rotten_tomatoes_rating"`
+	CreatedAt            time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at" db:"updated_at"`
+	Providers            interface{} `json:"providers,omitempty"`
+	Credits              *Credits    `json:"credits,omitempty"`
+	Videos               *VideoList  `json:"videos,omitempty"`
+	Images               *Images     `json:"images,omitempty"`
+	Trailer              string      `json:"trailer,omitempty"`
+	BelongsToCollection  *Collection `json:"belongs_to_collection,omitempty"`
+}
+
+// TVShow represents a TV show from TMDB
+type TVShow struct {
+	ID                   int        `json:"id" db:"id"`
+	TMDBID               int        `json:"tmdb_id" db:"tmdb_id"`
+	Name                 string     `json:"name" db:"name"`
+	Overview             string     `json:"overview" db:"overview"`
+	PosterPath           string     `json:"poster_path" db:"poster_path"`
+	BackdropPath         string     `json:"backdrop_path" db:"backdrop_path"`
+	FirstAirDate         string     `json:"first_air_date" db:"first_air_date"`
+	LastAirDate          string     `json:"last_air_date" db:"last_air_date"`
+	VoteAverage          float64    `json:"vote_average" db:"vote_average"`
+	VoteCount            int        `json:"vote_count" db:"vote_count"`
+	Popularity           float64    `json:"popularity" db:"popularity"`
+	GenreIDs             []int      `json:"genre_ids" db:"genre_ids"`
+	NumberOfSeasons      int        `json:"number_of_seasons" db:"number_of_seasons"`
+	NumberOfEpisodes     int        `json:"number_of_episodes" db:"number_of_episodes"`
+	Status               string     `json:"status" db:"status"`
+	Type                 string     `json:"type" db:"type"`
+	IMDBRating           string     `json:"imdb_rating" db:"imdb_rating"`
+	RottenTomatoesRating string     `json:"rotten_tomatoes_rating" db:"rotten_tomatoes_rating"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	NextEpisodeToAir     *Episode   `json:"next_episode_to_air,omitempty"`
+	Seasons              []Season   `json:"seasons,omitempty"`
+	Credits              *Credits   `json:"credits,omitempty"`
+	Videos               *VideoList `json:"videos,omitempty"`
+	Images               *Images    `json:"images,omitempty"`
+	Trailer              string     `json:"trailer,omitempty"`
+}
+
+// Credits lists the cast and crew of a title
+type Credits struct {
+	Cast []CastMember `json:"cast"`
+	Crew []CrewMember `json:"crew"`
+}
+
+// CastMember is a person playing a character; Order is their billing
+type CastMember struct {
+	ID          int    `json:"id"`
+	CreditID    string `json:"credit_id"`
+	Name        string `json:"name"`
+	Character   string `json:"character"`
+	ProfilePath string `json:"profile_path"`
+	Order       int    `json:"order"`
+}
+
+// CrewMember is a person credited behind the camera
+type CrewMember struct {
+	ID          int    `json:"id"`
+	CreditID    string `json:"credit_id"`
+	Name        string `json:"name"`
+	Job         string `json:"job"`
+	Department  string `json:"department"`
+	ProfilePath string `json:"profile_path"`
+}
+
+// VideoList holds a title's trailers, teasers and clips
+type VideoList struct {
+	Results []Video `json:"results"`
+}
+
+// Video is a video hosted on YouTube or Vimeo; Key identifies it there
+type Video struct {
+	ID          string `json:"id"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Site        string `json:"site"`
+	Type        string `json:"type"`
+	Official    bool   `json:"official"`
+	Language    string `json:"iso_639_1"`
+	PublishedAt string `json:"published_at"`
+}
+
+// Trailer returns the YouTube key of the title's trailer, preferring
+// official ones, or "" when there is none
+func (v *VideoList) Trailer() string {
+	if v == nil {
+		return ""
+	}
+	key := ""
+	for _, video := range v.Results {
+		if video.Site != "YouTube" || video.Type != "Trailer" || video.Key == "" {
+			continue
+		}
+		if video.Official {
+			return video.Key
+		}
+		if key == "" {
+			key = video.Key
+		}
+	}
+	return key
+}
+
+// Images holds a title's artwork. Episodes only have stills.
+type Images struct {
+	Backdrops []Image `json:"backdrops"`
+	Posters   []Image `json:"posters"`
+	Logos     []Image `json:"logos"`
+	Stills    []Image `json:"stills,omitempty"`
+}
+
+// Image is one piece of artwork; FilePath is relative to TMDB's image CDN
+type Image struct {
+	FilePath    string  `json:"file_path"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	AspectRatio float64 `json:"aspect_ratio"`
+	Language    string  `json:"iso_639_1"`
+	VoteAverage float64 `json:"vote_average"`
+}
+
+// Episode represents a single TV episode from TMDB
+type Episode struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	Overview      string  `json:"overview"`
+	AirDate       string  `json:"air_date"`
+	SeasonNumber  int     `json:"season_number"`
+	EpisodeNumber int     `json:"episode_number"`
+	Runtime       int     `json:"runtime"`
+	StillPath     string  `json:"still_path"`
+	VoteAverage   float64 `json:"vote_average"`
+
+	GuestStars []CastMember `json:"guest_stars,omitempty"`
+	Crew       []CrewMember `json:"crew,omitempty"`
+	Images     *Images      `json:"images,omitempty"`
+	// Watched is the requesting user's watched state, present once they
+	// track episodes of the show
+	Watched *bool `json:"watched,omitempty"`
+}
+
+// Season is one season of a TV show. Show details list seasons with an
+// EpisodeCount; season details carry the Episodes themselves.
+type Season struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Overview     string    `json:"overview"`
+	AirDate      string    `json:"air_date"`
+	SeasonNumber int       `json:"season_number"`
+	PosterPath   string    `json:"poster_path"`
+	VoteAverage  float64   `json:"vote_average"`
+	EpisodeCount int       `json:"episode_count,omitempty"`
+	Episodes     []Episode `json:"episodes,omitempty"`
+}
+
+// Collection groups the movies of a franchise, such as a trilogy. Movie
+// details name the collection; collection details list its Parts.
+type Collection struct {
+	ID           int              `json:"id"`
+	Name         string           `json:"name"`
+	Overview     string           `json:"overview,omitempty"`
+	PosterPath   string           `json:"poster_path"`
+	BackdropPath string           `json:"backdrop_path"`
+	Parts        []CollectionPart `json:"parts,omitempty"`
+}
+
+// CollectionPart is one movie of a collection
+type CollectionPart struct {
+	ResultItem
+
+	// OnWatchlist and Watched reflect the requesting user's watchlist
+	OnWatchlist bool `json:"on_watchlist"`
+	Watched     bool `json:"watched"`
+}
+
+// WatchedEpisode records that a user watched one episode of a show
+type WatchedEpisode struct {
+	UserID        string    `json:"user_id" db:"user_id"`
+	ShowID        int       `json:"show_id" db:"show_id"`
+	SeasonNumber  int       `json:"season_number" db:"season_number"`
+	EpisodeNumber int       `json:"episode_number" db:"episode_number"`
+	WatchedAt     time.Time `json:"watched_at" db:"watched_at"`
+}
+
+// WatchlistItem represents an item in user's watchlist
+type WatchlistItem struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	ContentID   int        `json:"content_id" db:"content_id"`
+	ContentType string     `json:"content_type" db:"content_type"` // "movie" or "tv"
+	IsWatched   bool       `json:"is_watched" db:"is_watched"`
+	AddedAt     time.Time  `json:"added_at" db:"added_at"`
+	WatchedAt   *time.Time `json:"watched_at" db:"watched_at"`
+}
+
+// WatchlistEntry is a watchlist item together with the title's details:
+// a TitleSummary unless other fields were requested
+type WatchlistEntry struct {
+	ContentID   int         `json:"contentId"`
+	ContentType string      `json:"contentType"`
+	IsWatched   bool        `json:"isWatched"`
+	Details     interface{} `json:"details"`
+}
+
+// TitleSummary is the compact form of a movie or TV show used to render
+// cards in lists
+type TitleSummary struct {
+	ID          int     `json:"id"`
+	MediaType   string  `json:"media_type"`
+	Title       string  `json:"title"`
+	Year        int     `json:"year,omitempty"`
+	PosterPath  string  `json:"poster_path"`
+	VoteAverage float64 `json:"vote_average"`
+	Runtime     int     `json:"runtime,omitempty"`
+}
+
+// Genre represents a movie/TV show genre. TMDB keeps separate lists for
+// movies and TV; genres on both share an ID.
+type Genre struct {
+	ID   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	// MediaTypes holds "movie", "tv" or both
+	MediaTypes []string `json:"media_types"`
+}
+
+// HasMediaType reports whether the genre is used for mediaType
+func (g Genre) HasMediaType(mediaType string) bool {
+	for _, t := range g.MediaTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult represents a search result
+type SearchResult struct {
+	Page         int          `json:"page"`
+	Results      []ResultItem `json:"results"`
+	TotalPages   int          `json:"total_pages"`
+	TotalResults int          `json:"total_results"`
+}
+
+// TrendingResult represents trending content
+type TrendingResult struct {
+	Page         int          `json:"page"`
+	Results      []ResultItem `json:"results"`
+	TotalPages   int          `json:"total_pages"`
+	TotalResults int          `json:"total_results"`
+}
+
+// Media types a ResultItem can have
+const (
+	MediaTypeMovie  = "movie"
+	MediaTypeTV     = "tv"
+	MediaTypePerson = "person"
+)
+
+// ResultItem is one movie, TV show or person in a TMDB result list, told
+// apart by MediaType. TMDB names a show's title and date "name" and
+// "first_air_date"; they are normalized to Title and ReleaseDate so every
+// media type reads the same. Person results use Title for the name and
+// carry a profile picture and the titles they are known for instead.
+type ResultItem struct {
+	ID               int     `json:"id"`
+	MediaType        string  `json:"media_type"`
+	Title            string  `json:"title"`
+	OriginalTitle    string  `json:"original_title,omitempty"`
+	OriginalLanguage string  `json:"original_language,omitempty"`
+	Overview         string  `json:"overview,omitempty"`
+	ReleaseDate      string  `json:"release_date,omitempty"`
+	PosterPath       string  `json:"poster_path,omitempty"`
+	BackdropPath     string  `json:"backdrop_path,omitempty"`
+	GenreIDs         []int   `json:"genre_ids,omitempty"`
+	VoteAverage      float64 `json:"vote_average"`
+	VoteCount        int     `json:"vote_count"`
+	Popularity       float64 `json:"popularity"`
+	Adult            bool    `json:"adult,omitempty"`
+
+	ProfilePath        string       `json:"profile_path,omitempty"`
+	KnownForDepartment string       `json:"known_for_department,omitempty"`
+	KnownFor           []ResultItem `json:"known_for,omitempty"`
+}
+
+// UnmarshalJSON decodes a TMDB result, normalizing TV and person names
+func (r *ResultItem) UnmarshalJSON(data []byte) error {
+	type item ResultItem
+	var raw struct {
+		item
+		Name         string `json:"name"`
+		OriginalName string `json:"original_name"`
+		FirstAirDate string `json:"first_air_date"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = ResultItem(raw.item)
+	if r.Title == "" {
+		r.Title = raw.Name
+	}
+	if r.OriginalTitle == "" {
+		r.OriginalTitle = raw.OriginalName
+	}
+	if r.ReleaseDate == "" {
+		r.ReleaseDate = raw.FirstAirDate
+	}
+	return nil
+}
+
+// Year returns the release year, or 0 when it is unknown
+func (r ResultItem) Year() int {
+	return ReleaseYear(r.ReleaseDate)
+}
+
+// PosterURL returns the item's poster, or a person's profile picture, at
+// a TMDB image size such as "w500"; "" when there is none
+func (r ResultItem) PosterURL(size string) string {
+	if r.MediaType == MediaTypePerson {
+		return ImageURL(r.ProfilePath, size)
+	}
+	return ImageURL(r.PosterPath, size)
+}
+
+// ImageBaseURL is where TMDB serves images; a size and file path follow
+const ImageBaseURL = "https://image.tmdb.org/t/p/"
+
+// ImageURL builds the URL of a TMDB image path at a size such as "w500"
+// or "original", or returns "" for an empty path
+func ImageURL(path, size string) string {
+	if path == "" {
+		return ""
+	}
+	return ImageBaseURL + size + path
+}
+
+// ReleaseYear returns the year of a TMDB date, or 0 when it is unknown
+func ReleaseYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, _ := strconv.Atoi(date[:4])
+	return year
+}
+
+// Person is an actor, director or anyone else credited on a title
+type Person struct {
+	ID                 int      `json:"id"`
+	Name               string   `json:"name"`
+	AlsoKnownAs        []string `json:"also_known_as"`
+	Biography          string   `json:"biography"`
+	Birthday           string   `json:"birthday,omitempty"`
+	Deathday           string   `json:"deathday,omitempty"`
+	PlaceOfBirth       string   `json:"place_of_birth,omitempty"`
+	Gender             int      `json:"gender"`
+	KnownForDepartment string   `json:"known_for_department"`
+	ProfilePath        string   `json:"profile_path,omitempty"`
+	IMDBID             string   `json:"imdb_id,omitempty"`
+	Homepage           string   `json:"homepage,omitempty"`
+	Popularity         float64  `json:"popularity"`
+	Adult              bool     `json:"adult"`
+
+	// Filmography holds the person's movie and TV credits, newest first
+	Filmography *PersonCredits `json:"filmography,omitempty"`
+}
+
+// PersonCredits are a person's combined movie and TV credits
+type PersonCredits struct {
+	Cast []PersonCredit `json:"cast"`
+	Crew []PersonCredit `json:"crew"`
+}
+
+// PersonCredit is one title a person worked on and their role in it. A
+// person credited in several crew jobs on one title appears once per job.
+type PersonCredit struct {
+	ResultItem
+	CreditRole
+
+	// OnWatchlist and Watched reflect the requesting user's watchlist
+	OnWatchlist bool `json:"on_watchlist"`
+	Watched     bool `json:"watched"`
+}
+
+// CreditRole is a cast member's character or a crew member's job
+type CreditRole struct {
+	CreditID     string `json:"credit_id"`
+	Character    string `json:"character,omitempty"`
+	Department   string `json:"department,omitempty"`
+	Job          string `json:"job,omitempty"`
+	EpisodeCount int    `json:"episode_count,omitempty"`
+}
+
+// UnmarshalJSON decodes a TMDB credit; the title's fields are normalized
+// like any other ResultItem
+func (c *PersonCredit) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.ResultItem); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.CreditRole)
+}
+
+// APIResponse is the envelope every JSON endpoint responds with. Failed
+// requests carry a human-readable Error and a machine-readable Code.
+type APIResponse struct {
+	Success    bool          `json:"success"`
+	Data       interface{}   `json:"data,omitempty"`
+	Pagination *Pagination   `json:"pagination,omitempty"`
+	Facets     *SearchFacets `json:"facets,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Code       string        `json:"code,omitempty"`
+	Message    string        `json:"message,omitempty"`
+}
+
+// Error codes reported in APIResponse.Code
+const (
+	ErrCodeInvalidRequest      = "INVALID_REQUEST"
+	ErrCodeInvalidID           = "INVALID_ID"
+	ErrCodeNotFound            = "NOT_FOUND"
+	ErrCodeUnauthorized        = "UNAUTHORIZED"
+	ErrCodeForbidden           = "FORBIDDEN"
+	ErrCodeMethodNotAllowed    = "METHOD_NOT_ALLOWED"
+	ErrCodePayloadTooLarge     = "PAYLOAD_TOO_LARGE"
+	ErrCodeRateLimited         = "RATE_LIMITED"
+	ErrCodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	ErrCodeInternal            = "INTERNAL_ERROR"
+)
+
+// ErrorCodes lists every code an APIResponse may carry
+var ErrorCodes = []string{
+	ErrCodeInvalidRequest, ErrCodeInvalidID, ErrCodeNotFound, ErrCodeUnauthorized, ErrCodeForbidden, ErrCodeMethodNotAllowed,
+	ErrCodePayloadTooLarge, ErrCodeRateLimited, ErrCodeUpstreamUnavailable, ErrCodeInternal,
+}
+
+// Pagination represents pagination metadata
+type Pagination struct {
+	Page         int `json:"page"`
+	TotalPages   int `json:"total_pages"`
+	TotalResults int `json:"total_results"`
+	PerPage      int `json:"per_page"`
+	// Truncated is set when filters only saw the top results, so the
+	// totals count matches among those rather than among every result
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// UserSettings holds a user's preferences. Adult titles are only
+// searchable once a user opts in.
+type UserSettings struct {
+	UserID       string     `json:"user_id" db:"user_id"`
+	IncludeAdult bool       `json:"include_adult" db:"include_adult"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// SearchFacets counts search results by genre and decade: the filtered
+// results when filters apply, otherwise the results on the page
+type SearchFacets struct {
+	Genres  []FacetCount `json:"genres"`
+	Decades []FacetCount `json:"decades"`
+}
+
+// FacetCount is the number of results sharing one facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// Webhook represents a user-registered outgoing webhook endpoint
+type Webhook struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	URL       string    `json:"url" db:"url"`
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	Events    []string  `json:"events" db:"events"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookDelivery represents a single delivery attempt of a webhook event
+type WebhookDelivery struct {
+	ID         int       `json:"id" db:"id"`
+	WebhookID  int       `json:"webhook_id" db:"webhook_id"`
+	EventID    string    `json:"event_id" db:"event_id"`
+	Event      string    `json:"event" db:"event"`
+	Attempt    int       `json:"attempt" db:"attempt"`
+	StatusCode int       `json:"status_code" db:"status_code"`
+	Success    bool      `json:"success" db:"success"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	DurationMS int64     `json:"duration_ms" db:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookEvent is the JSON payload POSTed to webhook endpoints
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	UserID    string      `json:"user_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Kinds of things a user can follow
+const (
+	FollowPerson  = "person"
+	FollowCompany = "company"
+)
+
+// FollowTarget is a person or production company that is followed
+type FollowTarget struct {
+	TargetType string `json:"target_type" db:"target_type"` // "person" or "company"
+	TargetID   int    `json:"target_id" db:"target_id"`
+	Name       string `json:"name" db:"name"`
+	// CheckedAt is when the target's titles were last scanned; nil until
+	// the first scan
+	CheckedAt *time.Time `json:"checked_at" db:"checked_at"`
+}
+
+// Follow is a user following a person or company for new titles
+type Follow struct {
+	UserID string `json:"user_id" db:"user_id"`
+	FollowTarget
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// FollowedTitle is a title credited to a followed person or company. Titles
+// found on the first scan of a target are its baseline; later ones were
+// announced while it was followed.
+type FollowedTitle struct {
+	TargetType   string    `json:"target_type" db:"target_type"`
+	TargetID     int       `json:"target_id" db:"target_id"`
+	TargetName   string    `json:"target_name" db:"-"`
+	ContentID    int       `json:"content_id" db:"content_id"`
+	ContentType  string    `json:"content_type" db:"content_type"`
+	Title        string    `json:"title" db:"title"`
+	ReleaseDate  string    `json:"release_date,omitempty" db:"release_date"`
+	PosterPath   string    `json:"poster_path,omitempty" db:"poster_path"`
+	Role         string    `json:"role,omitempty" db:"role"`
+	DiscoveredAt time.Time `json:"discovered_at" db:"discovered_at"`
+}
+
+// Company is a production company
+type Company struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	Headquarters  string `json:"headquarters,omitempty"`
+	Homepage      string `json:"homepage,omitempty"`
+	LogoPath      string `json:"logo_path,omitempty"`
+	OriginCountry string `json:"origin_country,omitempty"`
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"binge-base/models"
+	"binge-base/openapi"
+	"binge-base/services"
+)
+
+const (
+	openAPIPath = "/api/openapi.json"
+	apiDocsPath = "/api/docs"
+)
+
+// apiSpec describes every registered route. The tests fail when a route
+// is missing here, so new endpoints have to be documented to ship.
+func apiSpec() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "BingeBase API",
+		Version:     version,
+		Description: "Search movies and TV shows, browse trending titles and keep a personal watchlist.",
+	})
+	envelope := doc.SchemaOf(models.APIResponse{})
+	doc.Components.Schemas["APIResponse"].Properties["code"] = openapi.Enum("Machine-readable error code", models.ErrorCodes...)
+	userID := openapi.QueryParam("user_id", "User the request acts on", false, &openapi.Schema{Type: "string", Default: "default_user"})
+	page := openapi.QueryParam("page", "Page number, starting at 1", false, &openapi.Schema{Type: "integer", Default: 1})
+	perPage := openapi.QueryParam("per_page", fmt.Sprintf("Results per page, at most %d", maxPerPage), false, &openapi.Schema{Type: "integer", Default: defaultPerPage})
+	fields := openapi.QueryParam("fields", "Comma-separated fields to return for each title; id and media_type are always included", false, openapi.String(""))
+	// Localized operations answer in the requested language, falling back
+	// to English for fields TMDB has no translation of
+	locale := []openapi.Parameter{
+		openapi.QueryParam("lang", "Language of titles, overviews and genre names, such as fr or es-MX; overrides Accept-Language", false, &openapi.Schema{Type: "string", Default: services.DefaultLanguage}),
+		openapi.QueryParam("region", "ISO 3166-1 country for regional results; defaults to the country in lang", false, openapi.String("")),
+		openapi.HeaderParam("Accept-Language", "Preferred languages when lang is not given", openapi.String("")),
+	}
+	localized := func(params ...openapi.Parameter) []openapi.Parameter {
+		return append(params, locale...)
+	}
+	numericID := func(what string) openapi.Parameter {
+		return openapi.PathParam("id", what+" ID", openapi.Integer(""))
+	}
+
+	op := func(tag, summary string, ok *openapi.Response, params ...openapi.Parameter) *openapi.Operation {
+		return &openapi.Operation{
+			Summary:    summary,
+			Tags:       []string{tag},
+			Parameters: params,
+			Responses: map[string]*openapi.Response{
+				"200":     ok,
+				"429":     openapi.JSON("Rate limit exceeded; see the Retry-After header", envelope),
+				"default": openapi.JSON("Error", envelope),
+			},
+		}
+	}
+	data := func(description string, schema *openapi.Schema) *openapi.Response {
+		return openapi.JSON(description, openapi.Object(map[string]*openapi.Schema{
+			"success": openapi.Boolean(""),
+			"data":    schema,
+		}))
+	}
+	paged := func(description string, items *openapi.Schema) *openapi.Response {
+		return openapi.JSON(description, openapi.Object(map[string]*openapi.Schema{
+			"success":    openapi.Boolean(""),
+			"data":       {Type: "array", Items: items},
+			"pagination": doc.SchemaOf(models.Pagination{}),
+		}))
+	}
+	message := openapi.JSON("Success", envelope)
+	withBody := func(o *openapi.Operation, body interface{}) *openapi.Operation {
+		o.RequestBody = openapi.JSONBody(doc.SchemaOf(body))
+		o.Responses["413"] = openapi.JSON("Request body too large", envelope)
+		return o
+	}
+	searchResult := doc.SchemaOf(models.ResultItem{})
+	// Operations
+	doc.Add(http.MethodGet, "/metrics", &openapi.Operation{
+		Summary:     "Prometheus metrics",
+		Description: "Requires an Authorization: Bearer header carrying METRICS_TOKEN. The endpoint is disabled when no token is configured.",
+		Tags:        []string{"operations"},
+		Parameters:  []openapi.Parameter{openapi.HeaderParam("Authorization", "Bearer METRICS_TOKEN", openapi.String(""))},
+		Responses: map[string]*openapi.Response{
+			"200": openapi.Content("Metrics in the Prometheus text format", "text/plain", openapi.String("")),
+			"401": openapi.JSON("Missing or wrong token", envelope),
+			"404": openapi.JSON("No metrics token is configured", envelope),
+		},
+	})
+	readiness := doc.SchemaOf(readinessReport{})
+	for _, path := range []string{"/api/v1/health", "/api/v1/health/ready"} {
+		o := op("operations", "Readiness check", openapi.JSON("Ready to serve traffic", readiness))
+		o.Responses["503"] = openapi.JSON("A required dependency is down or the server is shutting down", readiness)
+		doc.Add(http.MethodGet, path, o)
+	}
+	doc.Add(http.MethodGet, "/api/v1/health/live", op("operations", "Liveness check", openapi.JSON("The process is serving requests", doc.SchemaOf(livenessReport{}))))
+	doc.Add(http.MethodGet, openAPIPath, &openapi.Operation{
+		Summary:   "This OpenAPI document",
+		Tags:      []string{"operations"},
+		Responses: map[string]*openapi.Response{"200": openapi.JSON("OpenAPI 3 document", &openapi.Schema{Type: "object"})},
+	})
+	doc.Add(http.MethodGet, apiDocsPath, &openapi.Operation{
+		Summary:   "Interactive API documentation",
+		Tags:      []string{"operations"},
+		Responses: map[string]*openapi.Response{"200": openapi.Content("HTML page", "text/html", openapi.String(""))},
+	})
+	doc.Add(http.MethodGet, apiDocsPath+"/{file}", &openapi.Operation{
+		Summary:     "Swagger UI assets for the documentation page",
+		Description: "swagger-ui-dist " + openapi.SwaggerUIVersion + ", embedded in the binary.",
+		Tags:        []string{"operations"},
+		Parameters:  []openapi.Parameter{openapi.PathParam("file", "swagger-ui.css or swagger-ui-bundle.js", openapi.String(""))},
+		Responses: map[string]*openapi.Response{
+			"200": openapi.Content("Stylesheet or script", "text/javascript", openapi.String("")),
+			"404": openapi.Content("Unknown file", "text/plain", openapi.String("")),
+		},
+	})
+
+	searchParams := localized(
+		page, perPage, userID, fields,
+		openapi.QueryParam("year", "Release year of movies, first air year of TV shows", false, openapi.Integer("")),
+		openapi.QueryParam("genre", "Comma-separated genre IDs; results match any of them", false, openapi.String("")),
+		openapi.QueryParam("min_rating", "Minimum TMDB vote average, 0-10", false, &openapi.Schema{Type: "number"}),
+		openapi.QueryParam("language", "ISO 639-1 original language, such as en or ko", false, openapi.String("")),
+		openapi.QueryParam("include_adult", "Include adult titles; the user must have enabled them in their settings", false, openapi.Boolean("")),
+		openapi.QueryParam("sort", "Result order", false, &openapi.Schema{Type: "string", Enum: searchSorts, Default: "relevance"}),
+	)
+	searched := func(description string) *openapi.Response {
+		response := paged(description, searchResult)
+		response.Content["application/json"].Schema.Properties["facets"] = doc.SchemaOf(models.SearchFacets{})
+		return response
+	}
+	searchDescription := fmt.Sprintf("Without a query, year, genre, rating, language and sorting are applied by TMDB across its whole catalogue. "+
+		"With one, genre, rating, language and sorting are applied to the top %d results per media type, "+
+		"and pagination.truncated is set when more results exist beyond them. Facets count the results fetched for the response.", searchWindowPages*tmdbPageSize)
+	for _, search := range []struct{ path, summary, results string }{
+		{"/api/v1/search", "Search movies, TV shows and people", "Movie, TV and person results interleaved by rank, then popularity"},
+		{"/api/v1/search/movies", "Search movies", "Movie results"},
+		{"/api/v1/search/tv", "Search TV shows", "TV results"},
+		{"/api/v1/search/people", "Search actors, directors and crew", "Person results"},
+	} {
+		query := openapi.QueryParam("query", "Search terms; optional when a year, genre, min_rating, language or sort is given", false, openapi.String(""))
+		if search.path == "/api/v1/search/people" {
+			query = openapi.QueryParam("query", "Search terms", true, openapi.String(""))
+		}
+		o := op("search", search.summary, searched(search.results), append([]openapi.Parameter{query}, searchParams...)...)
+		o.Description = searchDescription + " People have no year, genre, rating or language, so those filters leave them out of the results."
+		o.Responses["403"] = openapi.JSON("Adult titles requested but not enabled for the user", envelope)
+		doc.Add(http.MethodGet, search.path, o)
+	}
+	doc.Add(http.MethodGet, "/api/v1/trending", op("trending", "Trending movies and TV shows this week", paged("Movies and TV shows interleaved by rank, then popularity", searchResult), localized(page, perPage, fields)...))
+	doc.Add(http.MethodGet, "/api/v1/trending/movies", op("trending", "Trending movies this week", paged("Trending movies", searchResult), localized(page, perPage, fields)...))
+	doc.Add(http.MethodGet, "/api/v1/trending/tv", op("trending", "Trending TV shows this week", paged("Trending TV shows", searchResult), localized(page, perPage, fields)...))
+
+	doc.Add(http.MethodGet, "/api/v1/genres", op("genres", "Movie and TV genres", data("Genres sorted by name", &openapi.Schema{Type: "array", Items: doc.SchemaOf(models.Genre{})}),
+		localized(openapi.QueryParam("type", "Only genres used for this content type", false, openapi.Enum("", "movie", "tv")))...))
+	doc.Add(http.MethodGet, "/api/v1/genres/{id}/{type}", op("genres", "Popular titles in a genre", paged("Titles", searchResult),
+		localized(numericID("Genre"), openapi.PathParam("type", "Content type", openapi.Enum("", "movie", "tv")), page, perPage, fields)...))
+
+	doc.Add(http.MethodGet, "/api/v1/movie/{id}", op("titles", "Movie details", data("Movie", doc.SchemaOf(models.Movie{})), localized(numericID("TMDB movie"))...))
+	doc.Add(http.MethodGet, "/api/v1/movie/{id}/providers", op("titles", "Streaming providers for a movie", data("Providers by region", &openapi.Schema{Type: "object"}), numericID("TMDB movie")))
+	doc.Add(http.MethodGet, "/api/v1/tv/{id}", op("titles", "TV show details", data("TV show", doc.SchemaOf(models.TVShow{})), localized(numericID("TMDB TV show"))...))
+	seasonNumber := openapi.PathParam("season", "Season number; 0 holds specials", openapi.Integer(""))
+	episodes := "Episodes carry the user's watched state once they have marked any episode of the show watched."
+	season := op("titles", "A TV season and its episodes", data("Season", doc.SchemaOf(models.Season{})), localized(numericID("TMDB TV show"), seasonNumber, userID)...)
+	season.Description = episodes
+	doc.Add(http.MethodGet, "/api/v1/tv/{id}/season/{season}", season)
+	episode := op("titles", "A TV episode with guest stars, crew and stills", data("Episode", doc.SchemaOf(models.Episode{})),
+		localized(numericID("TMDB TV show"), seasonNumber, openapi.PathParam("episode", "Episode number", openapi.Integer("")), userID)...)
+	episode.Description = episodes
+	doc.Add(http.MethodGet, "/api/v1/tv/{id}/season/{season}/episode/{episode}", episode)
+	person := op("people", "Person details and filmography", data("Person", doc.SchemaOf(models.Person{})), localized(numericID("TMDB person"), userID)...)
+	person.Description = "Credits are marked with whether the title is on the user's watchlist and watched. Adult titles are omitted unless the user has enabled them."
+	doc.Add(http.MethodGet, "/api/v1/person/{id}", person)
+	collection := op("titles", "A movie collection and its parts in release order", data("Collection", doc.SchemaOf(models.Collection{})), localized(numericID("TMDB collection"), userID)...)
+	collection.Description = "Parts are marked with whether the movie is on the user's watchlist and watched."
+	doc.Add(http.MethodGet, "/api/v1/collection/{id}", collection)
+
+	doc.Add(http.MethodGet, "/api/v1/settings", op("settings", "A user's settings", data("Settings", doc.SchemaOf(models.UserSettings{})), userID))
+	doc.Add(http.MethodPut, "/api/v1/settings", withBody(op("settings", "Save a user's settings", data("Saved settings", doc.SchemaOf(models.UserSettings{}))), updateSettingsRequest{}))
+
+	watchlist := op("watchlist", "List a user's watchlist with title details", data("Watchlist entries", &openapi.Schema{Type: "array", Items: doc.SchemaOf(models.WatchlistEntry{})}),
+		localized(userID, openapi.QueryParam("fields", `Detail fields to return, or "full" for complete Movie and TVShow details; a TitleSummary by default`, false, openapi.String("")))...)
+	watchlist.Description = "Each entry's details are a TitleSummary unless fields is given."
+	doc.SchemaOf(models.TitleSummary{})
+	doc.Add(http.MethodGet, "/api/v1/watchlist", watchlist)
+	doc.Add(http.MethodPost, "/api/v1/watchlist", withBody(op("watchlist", "Add a title to a watchlist", message), addWatchlistRequest{}))
+	doc.Add(http.MethodPut, "/api/v1/watchlist", withBody(op("watchlist", "Mark a watchlist title as watched or unwatched", message), updateWatchlistRequest{}))
+	doc.Add(http.MethodPut, "/api/v1/watchlist/episodes", withBody(op("watchlist", "Mark a TV episode as watched or unwatched", message), updateEpisodeRequest{}))
+	addCollection := withBody(op("watchlist", "Add every movie in a collection to a watchlist", data("Collection with updated watch state", doc.SchemaOf(models.Collection{})), localized()...), addCollectionRequest{})
+	addCollection.Description = "Movies already on the watchlist keep their watched state."
+	doc.Add(http.MethodPost, "/api/v1/watchlist/collection", addCollection)
+	doc.Add(http.MethodDelete, "/api/v1/watchlist", op("watchlist", "Remove a title from a watchlist", message, userID,
+		openapi.QueryParam("content_id", "TMDB ID of the title", true, openapi.Integer("")),
+		openapi.QueryParam("content_type", "", true, openapi.Enum("", "movie", "tv"))))
+
+	targetType := openapi.Enum("", models.FollowPerson, models.FollowCompany)
+	doc.Add(http.MethodGet, "/api/v1/follows", op("follows", "People and companies a user follows", data("Follows, newest first", &openapi.Schema{Type: "array", Items: doc.SchemaOf(models.Follow{})}), userID))
+	createFollow := withBody(op("follows", "Follow a person or production company", nil), followRequest{})
+	delete(createFollow.Responses, "200")
+	createFollow.Responses["201"] = data("The follow", doc.SchemaOf(models.Follow{}))
+	createFollow.Description = "Following someone nobody followed yet records what they are already credited with; titles announced after that appear in the user's releases. " +
+		"Credits are rescanned twice a day. For companies only titles dated from today on are tracked."
+	doc.Add(http.MethodPost, "/api/v1/follows", createFollow)
+	doc.Add(http.MethodDelete, "/api/v1/follows", op("follows", "Stop following a person or company", message, userID,
+		openapi.QueryParam("target_type", "", true, targetType),
+		openapi.QueryParam("target_id", "TMDB person or company ID", true, openapi.Integer(""))))
+	doc.Add(http.MethodGet, "/api/v1/follows/releases", op("follows", "New titles from followed people and companies", data("Titles, newest discovery first", &openapi.Schema{Type: "array", Items: doc.SchemaOf(models.FollowedTitle{})}),
+		userID, openapi.QueryParam("limit", "Maximum number of titles (at most 200)", false, &openapi.Schema{Type: "integer", Default: 50})))
+
+	webhookID := openapi.QueryParam("id", "Webhook ID", true, openapi.Integer(""))
+	doc.Add(http.MethodGet, "/api/v1/webhooks", op("webhooks", "List a user's webhooks (secrets are omitted)", data("Webhooks", &openapi.Schema{Type: "array", Items: doc.SchemaOf(models.Webhook{})}), userID))
+	createWebhook := withBody(op("webhooks", "Register a webhook", nil), createWebhookRequest{})
+	delete(createWebhook.Responses, "200")
+	createWebhook.Responses["201"] = data("The webhook, including its signing secret", doc.SchemaOf(models.Webhook{}))
+	createWebhook.Description = "Deliveries are POSTed as a WebhookEvent and signed in the " + services.SignatureHeader + " header. " +
+		"The URL must resolve to a public address; loopback, link-local and private networks are refused."
+	doc.SchemaOf(models.WebhookEvent{})
+	doc.Add(http.MethodPost, "/api/v1/webhooks", createWebhook)
+	doc.Add(http.MethodDelete, "/api/v1/webhooks", op("webhooks", "Delete a webhook", message, userID, webhookID))
+	doc.Add(http.MethodGet, "/api/v1/webhooks/deliveries", op("webhooks", "Recent delivery attempts for a webhook", data("Deliveries, newest first", &openapi.Schema{Type: "array", Items: doc.SchemaOf(models.WebhookDelivery{})}),
+		userID, openapi.QueryParam("webhook_id", "Webhook ID", true, openapi.Integer("")),
+		openapi.QueryParam("limit", "Maximum number of deliveries (at most 200)", false, &openapi.Schema{Type: "integer", Default: 50})))
+
+	atom := openapi.Content("Atom feed", "application/atom+xml", openapi.String(""))
+	doc.Add(http.MethodGet, "/feeds/trending.atom", op("feeds", "Trending titles as an Atom feed", atom, locale...))
+	doc.Add(http.MethodGet, "/feeds/activity.atom", op("feeds", "A user's watchlist activity as an Atom feed", atom, localized(userID)...))
+	doc.Add(http.MethodGet, "/feeds/following.atom", op("feeds", "New titles from people and companies a user follows, as an Atom feed", atom, userID))
+
+	// Cached routes answer conditional requests
+	for pattern := range cachePolicies {
+		item, ok := doc.Paths[pattern]
+		if !ok || (*item)["get"] == nil {
+			continue
+		}
+		o := (*item)["get"]
+		o.Parameters = append(o.Parameters, openapi.HeaderParam("If-None-Match", "ETag of a previous response", openapi.String("")))
+		o.Responses["304"] = &openapi.Response{Description: "Not modified; the cached response is still current"}
+	}
+	for _, pattern := range []string{"/api/v1/settings", "/api/v1/follows"} {
+		o := (*doc.Paths[pattern])["get"]
+		o.Parameters = append(o.Parameters, openapi.HeaderParam("If-Modified-Since", "Last-Modified of a previous response; ignored when If-None-Match is sent", openapi.String("")))
+	}
+
+	return doc
+}
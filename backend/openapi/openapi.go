@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"embed"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"binge-base/router"
+)
+
+// SwaggerUIVersion is the swagger-ui-dist release vendored in swagger-ui/
+const SwaggerUIVersion = "5.18.2"
+
+// docsFS holds the docs page and the Swagger UI assets it loads
+//
+//go:embed docs.html swagger-ui/*.css swagger-ui/*.js
+var docsFS embed.FS
+
+var swaggerUIFiles = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+}
+
+// Document is an OpenAPI 3.0 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+}
+
+// New creates an empty document
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      make(map[string]*PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+}
+
+// Add documents an operation. Router patterns are accepted as-is; a
+// trailing "{name...}" catch-all becomes a plain "{name}" parameter.
+func (d *Document) Add(method, pattern string, op *Operation) {
+	path := specPath(pattern)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// Missing returns the routes that have no matching operation in the document
+func (d *Document) Missing(routes []router.Route) []router.Route {
+	var missing []router.Route
+	for _, route := range routes {
+		item, ok := d.Paths[specPath(route.Pattern)]
+		if !ok || (*item)[strings.ToLower(route.Method)] == nil {
+			missing = append(missing, route)
+		}
+	}
+	return missing
+}
+
+// SchemaOf describes the JSON encoding of v's type. Named struct types are
+// added to the document's components and referenced, so every endpoint
+// returning a models type shares one definition.
+func (d *Document) SchemaOf(v interface{}) *Schema {
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t.Kind() == reflect.Pointer {
+		s := d.schemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// Reserve the name first so self-referencing types terminate
+			d.Components.Schemas[name] = &Schema{}
+			d.Components.Schemas[name] = d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// interface{} and anything else encodes as arbitrary JSON
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := d.structSchema(field.Type)
+			for prop, schema := range embedded.Properties {
+				s.Properties[prop] = schema
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = d.schemaOf(field.Type)
+	}
+	return s
+}
+
+// Object builds an inline object schema from named properties
+func Object(properties map[string]*Schema) *Schema {
+	return &Schema{Type: "object", Properties: properties}
+}
+
+// Ref references a schema in the document's components
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// String, Integer and Boolean build primitive schemas
+func String(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+func Integer(description string) *Schema {
+	return &Schema{Type: "integer", Description: description}
+}
+
+func Boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+// Enum builds a string schema restricted to values
+func Enum(description string, values ...string) *Schema {
+	return &Schema{Type: "string", Description: description, Enum: values}
+}
+
+// PathParam, QueryParam and HeaderParam build parameters. Path parameters
+// are always required.
+func PathParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+func QueryParam(name, description string, required bool, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Required: required, Schema: schema}
+}
+
+func HeaderParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: schema}
+}
+
+// JSONBody describes a required JSON request body
+func JSONBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+// JSON describes a response with a JSON body
+func JSON(description string, schema *Schema) *Response {
+	return Content(description, "application/json", schema)
+}
+
+// Content describes a response with a body of the given media type
+func Content(description, mediaType string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{mediaType: {Schema: schema}}}
+}
+
+// DocsHandler serves an interactive documentation page for the spec at
+// specURL. The page loads Swagger UI from assetsURL, where AssetsHandler
+// is mounted.
+func DocsHandler(specURL, assetsURL string) http.Handler {
+	page, err := docsFS.ReadFile("docs.html")
+	if err != nil {
+		panic("openapi: missing embedded docs page: " + err.Error())
+	}
+	page = []byte(strings.NewReplacer("{{SPEC_URL}}", specURL, "{{ASSETS_URL}}", assetsURL).Replace(string(page)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			w.Write(page)
+		}
+	})
+}
+
+// AssetsHandler serves the embedded Swagger UI files named by the "file"
+// route param
+func AssetsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := router.Param(r, "file")
+		contentType, ok := swaggerUIFiles[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, _ := docsFS.ReadFile("swagger-ui/" + name)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			w.Write(data)
+		}
+	})
+}
+
+func specPath(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "...}") {
+			parts[i] = strings.TrimSuffix(part, "...}") + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
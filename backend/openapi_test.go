@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"binge-base/config"
+)
+
+func TestSpecDocumentsEveryRoute(t *testing.T) {
+	s := &Server{config: &config.Config{}}
+	for _, route := range apiSpec().Missing(s.routes(nil).Routes()) {
+		t.Errorf("%s %s is registered but missing from the OpenAPI spec", route.Method, route.Pattern)
+	}
+}
+
+func TestSpecDocumentsCachedRoutes(t *testing.T) {
+	spec := apiSpec()
+	for pattern := range cachePolicies {
+		item, ok := spec.Paths[pattern]
+		if !ok || (*item)["get"] == nil {
+			t.Errorf("cache policy for %s, which the spec doesn't document", pattern)
+			continue
+		}
+		if (*item)["get"].Responses["304"] == nil {
+			t.Errorf("GET %s has no 304 response", pattern)
+		}
+	}
+}
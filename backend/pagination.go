@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"binge-base/models"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+
+	// TMDB returns fixed pages of 20 results and refuses pages past 500
+	tmdbPageSize = 20
+	tmdbMaxPages = 500
+)
+
+// resultSource is one TMDB result list that can be fetched page by page
+type resultSource struct {
+	mediaType string
+	fetch     func(ctx context.Context, page int) (*models.SearchResult, error)
+}
+
+// perPageParam reads the optional per_page query parameter, capped at maxPerPage
+func perPageParam(r *http.Request) int {
+	if n, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && n > 0 {
+		if n > maxPerPage {
+			return maxPerPage
+		}
+		return n
+	}
+	return defaultPerPage
+}
+
+// sourcePosition identifies one result by its source and rank in that source
+type sourcePosition struct {
+	source int
+	index  int
+}
+
+// mergedPage returns one page of the round-robin merge of sources: the
+// first result of every source, then the second of each, and so on, with
+// exhausted sources dropping out. Within a round results are ordered by
+// popularity. Because the merge only depends on ranks and totals, any
+// page maps straight to the TMDB pages it needs and earlier pages are
+// never fetched.
+func mergedPage(ctx context.Context, sources []resultSource, page, perPage int) ([]models.ResultItem, models.Pagination, error) {
+	from := (page - 1) * perPage
+	to := from + perPage
+	fetcher := newPageFetcher(ctx, sources)
+
+	// Every source contributes about one result per round, so this
+	// estimate lands on the right TMDB page unless other sources are
+	// already exhausted. Either way it tells us how many results exist.
+	totals := make([]int, len(sources))
+	for i := range sources {
+		estimate := from / len(sources)
+		total, err := fetcher.total(i, estimate/tmdbPageSize+1)
+		if err != nil {
+			return nil, models.Pagination{}, err
+		}
+		totals[i] = total
+	}
+
+	totalResults := 0
+	for _, total := range totals {
+		totalResults += total
+	}
+	pagination := models.Pagination{
+		Page:         page,
+		PerPage:      perPage,
+		TotalResults: totalResults,
+		TotalPages:   (totalResults + perPage - 1) / perPage,
+	}
+
+	results := []models.ResultItem{}
+	offset := 0
+	for round := 0; offset < to; round++ {
+		var positions []sourcePosition
+		for i, total := range totals {
+			if round < total {
+				positions = append(positions, sourcePosition{source: i, index: round})
+			}
+		}
+		if len(positions) == 0 {
+			break
+		}
+		// Only fetch rounds that overlap the requested page
+		if offset+len(positions) <= from {
+			offset += len(positions)
+			continue
+		}
+
+		var items []models.ResultItem
+		for _, pos := range positions {
+			item, ok, err := fetcher.item(pos)
+			if err != nil {
+				return nil, models.Pagination{}, err
+			}
+			if ok {
+				items = append(items, item)
+			}
+		}
+		sort.SliceStable(items, func(a, b int) bool {
+			return items[a].Popularity > items[b].Popularity
+		})
+		for _, item := range items {
+			if offset >= from && offset < to {
+				results = append(results, item)
+			}
+			offset++
+		}
+	}
+	return results, pagination, nil
+}
+
+// pageFetcher fetches each TMDB page at most once per request
+type pageFetcher struct {
+	ctx     context.Context
+	sources []resultSource
+	pages   map[[2]int]*models.SearchResult
+}
+
+func newPageFetcher(ctx context.Context, sources []resultSource) *pageFetcher {
+	return &pageFetcher{ctx: ctx, sources: sources, pages: make(map[[2]int]*models.SearchResult)}
+}
+
+func (f *pageFetcher) page(source, page int) (*models.SearchResult, error) {
+	key := [2]int{source, page}
+	if result, ok := f.pages[key]; ok {
+		return result, nil
+	}
+	result, err := f.sources[source].fetch(f.ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Results {
+		if result.Results[i].MediaType == "" {
+			result.Results[i].MediaType = f.sources[source].mediaType
+		}
+	}
+	f.pages[key] = result
+	return result, nil
+}
+
+// total reports how many results a source has, as far as TMDB will page
+func (f *pageFetcher) total(source, page int) (int, error) {
+	if page > tmdbMaxPages {
+		page = tmdbMaxPages
+	}
+	result, err := f.page(source, page)
+	if err != nil {
+		return 0, err
+	}
+	total := result.TotalResults
+	if limit := result.TotalPages * tmdbPageSize; limit < total {
+		total = limit
+	}
+	if limit := tmdbMaxPages * tmdbPageSize; limit < total {
+		total = limit
+	}
+	return total, nil
+}
+
+// item returns the result at a rank; ok is false when TMDB's page is
+// shorter than its reported total
+func (f *pageFetcher) item(pos sourcePosition) (item models.ResultItem, ok bool, err error) {
+	result, err := f.page(pos.source, pos.index/tmdbPageSize+1)
+	if err != nil {
+		return models.ResultItem{}, false, err
+	}
+	if i := pos.index % tmdbPageSize; i < len(result.Results) {
+		return result.Results[i], true, nil
+	}
+	return models.ResultItem{}, false, nil
+}
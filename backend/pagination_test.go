@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"binge-base/models"
+)
+
+// fakeSource serves size results in TMDB-sized pages and records which
+// pages were fetched. It reports reported results, which TMDB sometimes
+// overstates, when set.
+type fakeSource struct {
+	id       int
+	size     int
+	reported int
+	fetched  []int
+}
+
+func (f *fakeSource) resultID(rank int) int {
+	return f.id*100000 + rank
+}
+
+func (f *fakeSource) source(mediaType string) resultSource {
+	return resultSource{mediaType: mediaType, fetch: func(ctx context.Context, page int) (*models.SearchResult, error) {
+		f.fetched = append(f.fetched, page)
+		total := f.size
+		if f.reported > 0 {
+			total = f.reported
+		}
+		result := &models.SearchResult{
+			Page:         page,
+			TotalResults: total,
+			TotalPages:   (total + tmdbPageSize - 1) / tmdbPageSize,
+			Results:      []models.ResultItem{},
+		}
+		for rank := (page - 1) * tmdbPageSize; rank < page*tmdbPageSize && rank < f.size; rank++ {
+			id := f.resultID(rank)
+			result.Results = append(result.Results, models.ResultItem{ID: id, Popularity: float64(id * 37 % 101)})
+		}
+		return result, nil
+	}}
+}
+
+// naiveMerge builds the whole merge one round at a time
+func naiveMerge(fakes []*fakeSource) []int {
+	var ids []int
+	for round := 0; ; round++ {
+		var items []models.ResultItem
+		for _, f := range fakes {
+			if round < f.size {
+				id := f.resultID(round)
+				items = append(items, models.ResultItem{ID: id, Popularity: float64(id * 37 % 101)})
+			}
+		}
+		if len(items) == 0 {
+			return ids
+		}
+		sort.SliceStable(items, func(a, b int) bool {
+			return items[a].Popularity > items[b].Popularity
+		})
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+	}
+}
+
+func TestMergedPageMatchesFullMerge(t *testing.T) {
+	for _, sizes := range [][]int{{45, 7, 0}, {100, 100}, {3}, {61, 250, 19}} {
+		for _, perPage := range []int{1, 7, 20, 100} {
+			var fakes []*fakeSource
+			var sources []resultSource
+			for i, size := range sizes {
+				f := &fakeSource{id: i + 1, size: size}
+				fakes = append(fakes, f)
+				sources = append(sources, f.source("movie"))
+			}
+			want := naiveMerge(fakes)
+			pages := (len(want)+perPage-1)/perPage + 1
+
+			for page := 1; page <= pages; page++ {
+				name := fmt.Sprintf("sizes %v per_page %d page %d", sizes, perPage, page)
+				results, pagination, err := mergedPage(context.Background(), sources, page, perPage)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if pagination.TotalResults != len(want) || pagination.TotalPages != pages-1 {
+					t.Errorf("%s: %d results in %d pages, want %d in %d", name, pagination.TotalResults, pagination.TotalPages, len(want), pages-1)
+				}
+
+				from, to := min((page-1)*perPage, len(want)), min(page*perPage, len(want))
+				got := make([]int, len(results))
+				for i, item := range results {
+					got[i] = item.ID
+					if item.MediaType != "movie" {
+						t.Errorf("%s: result %d has media type %q", name, item.ID, item.MediaType)
+					}
+				}
+				if fmt.Sprint(got) != fmt.Sprint(want[from:to]) {
+					t.Errorf("%s: got %v, want %v", name, got, want[from:to])
+				}
+			}
+		}
+	}
+}
+
+func TestMergedPageSkipsEarlierPages(t *testing.T) {
+	a, b := &fakeSource{id: 1, size: 1000}, &fakeSource{id: 2, size: 1000}
+	sources := []resultSource{a.source("movie"), b.source("tv")}
+
+	// Results 780-799 are ranks 390-399 of each source, on TMDB page 20
+	if _, _, err := mergedPage(context.Background(), sources, 40, 20); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*fakeSource{a, b} {
+		if fmt.Sprint(f.fetched) != "[20]" {
+			t.Errorf("source %d fetched pages %v, want [20]", f.id, f.fetched)
+		}
+	}
+}
+
+func TestMergedPageExhaustedSource(t *testing.T) {
+	// The short source is done after 5 rounds, so the long one fills the
+	// rest of the merge and page 3 of 20 sits deeper in it than estimated
+	short, long := &fakeSource{id: 1, size: 5}, &fakeSource{id: 2, size: 200}
+	sources := []resultSource{short.source("movie"), long.source("tv")}
+
+	results, _, err := mergedPage(context.Background(), sources, 3, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 20 {
+		t.Fatalf("%d results, want 20", len(results))
+	}
+	for i, item := range results {
+		if want := long.resultID(35 + i); item.ID != want || item.MediaType != "tv" {
+			t.Errorf("result %d: %d %s, want %d tv", i, item.ID, item.MediaType, want)
+		}
+	}
+}
+
+func TestMergedPageShortTMDBPage(t *testing.T) {
+	// TMDB claims 30 results but only has 25
+	f := &fakeSource{id: 1, size: 25, reported: 30}
+	results, pagination, err := mergedPage(context.Background(), []resultSource{f.source("movie")}, 2, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 || pagination.TotalResults != 30 {
+		t.Errorf("%d results of %d, want 5 of 30", len(results), pagination.TotalResults)
+	}
+}
+
+func TestMergedPageCapsAtTMDBPageLimit(t *testing.T) {
+	f := &fakeSource{id: 1, size: 100, reported: 20000}
+	_, pagination, err := mergedPage(context.Background(), []resultSource{f.source("movie")}, 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := tmdbMaxPages * tmdbPageSize; pagination.TotalResults != want {
+		t.Errorf("total %d, want %d", pagination.TotalResults, want)
+	}
+
+	// A page past TMDB's limit is clamped instead of requested
+	f.fetched = nil
+	if _, _, err := mergedPage(context.Background(), []resultSource{f.source("movie")}, 600, 20); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(f.fetched) != fmt.Sprint([]int{tmdbMaxPages}) {
+		t.Errorf("fetched pages %v", f.fetched)
+	}
+}
+
+func TestMergedPageFetchError(t *testing.T) {
+	failing := resultSource{mediaType: "movie", fetch: func(ctx context.Context, page int) (*models.SearchResult, error) {
+		return nil, errors.New("upstream down")
+	}}
+	if _, _, err := mergedPage(context.Background(), []resultSource{failing}, 1, 20); err == nil {
+		t.Error("error not returned")
+	}
+}
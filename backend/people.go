@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"binge-base/models"
+	"binge-base/router"
+)
+
+// Person details handler. The filmography is marked with the user's
+// watchlist, and adult titles are left out unless the user enabled them.
+func (s *Server) personHandler(w http.ResponseWriter, r *http.Request) {
+	personID, err := router.IntParam(r, "id")
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid person ID")
+		return
+	}
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+
+	person, err := s.tmdbService.GetPersonDetails(r.Context(), personID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Person not found", "Failed to fetch person details")
+		return
+	}
+	credits, err := s.tmdbService.GetPersonCredits(r.Context(), personID)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "Person not found", "Failed to fetch filmography")
+		return
+	}
+	settings, err := s.db.GetUserSettings(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get user settings")
+		return
+	}
+	states, err := s.watchStates(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get watchlist")
+		return
+	}
+
+	for _, list := range []*[]models.PersonCredit{&credits.Cast, &credits.Crew} {
+		kept := (*list)[:0]
+		for _, credit := range *list {
+			if credit.Adult && !settings.IncludeAdult {
+				continue
+			}
+			credit.OnWatchlist, credit.Watched = states.lookup(credit.MediaType, credit.ID)
+			kept = append(kept, credit)
+		}
+		sortFilmography(kept)
+		*list = kept
+	}
+	person.Filmography = credits
+	s.sendData(w, person)
+}
+
+// sortFilmography orders credits newest first. Titles without a date are
+// usually announced but unreleased, so they lead.
+func sortFilmography(credits []models.PersonCredit) {
+	sort.SliceStable(credits, func(i, j int) bool {
+		a, b := credits[i].ReleaseDate, credits[j].ReleaseDate
+		if a == "" || b == "" {
+			return a == "" && b != ""
+		}
+		return a > b
+	})
+}
+
+// watchKey identifies a title on a watchlist
+type watchKey struct {
+	mediaType string
+	id        int
+}
+
+// watchStateSet maps a user's watchlist titles to whether they were watched
+type watchStateSet map[watchKey]bool
+
+func (s *Server) watchStates(userID string) (watchStateSet, error) {
+	items, err := s.db.GetWatchlistItems(userID)
+	if err != nil {
+		return nil, err
+	}
+	states := make(watchStateSet, len(items))
+	for _, item := range items {
+		states[watchKey{item.ContentType, item.ContentID}] = item.IsWatched
+	}
+	return states, nil
+}
+
+// lookup reports whether a title is on the watchlist and whether it was watched
+func (w watchStateSet) lookup(mediaType string, id int) (onWatchlist, watched bool) {
+	watched, onWatchlist = w[watchKey{mediaType, id}]
+	return onWatchlist, watched
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"binge-base/config"
+	"binge-base/logging"
+	"binge-base/metrics"
+	"binge-base/models"
+	"binge-base/router"
+)
+
+var httpRateLimited = metrics.NewCounterVec("bingebase_http_rate_limited_total",
+	"Requests rejected by the per-client rate limiter by route class.", "class")
+
+// Route classes share one limit each. Search and trending fan out to
+// several TMDB calls per request, so they get the tightest budget.
+const (
+	routeClassSearch = "search"
+	routeClassRead   = "read"
+	routeClassWrite  = "write"
+)
+
+// clientLimiter keeps a token bucket per client key. Buckets refill
+// continuously, allowing bursts up to the full limit.
+type clientLimiter struct {
+	window     time.Duration
+	limits     map[string]int
+	trustProxy bool
+	proxies    []*net.IPNet
+	now        func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+type clientBucket struct {
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+type limitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func newClientLimiter(cfg *config.Config) *clientLimiter {
+	return &clientLimiter{
+		window: time.Duration(cfg.RateLimitWindow) * time.Second,
+		limits: map[string]int{
+			routeClassSearch: cfg.RateLimitSearch,
+			routeClassRead:   cfg.RateLimitRead,
+			routeClassWrite:  cfg.RateLimitWrite,
+		},
+		trustProxy: cfg.RateLimitTrustProxy,
+		proxies:    parseProxyCIDRs(cfg.RateLimitProxyCIDRs),
+		now:        time.Now,
+		buckets:    make(map[string]*clientBucket),
+		lastSweep:  time.Now(),
+	}
+}
+
+// take spends one token from the key's bucket if it has one left
+func (l *clientLimiter) take(key string, limit int) limitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	rate := float64(limit) / l.window.Seconds()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &clientBucket{capacity: float64(limit), tokens: float64(limit), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	result := limitResult{limit: limit, allowed: b.tokens >= 1}
+	if result.allowed {
+		b.tokens--
+	} else {
+		result.retryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	result.remaining = int(b.tokens)
+	result.reset = time.Duration((float64(limit) - b.tokens) / rate * float64(time.Second))
+	return result
+}
+
+// sweep drops buckets that have refilled completely, since they behave
+// exactly like a fresh bucket. Runs at most once per window.
+func (l *clientLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.window {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Rate limit middleware. Registered on the router so requests are
+// classified by route pattern. Requests are limited by client IP only:
+// user_id is not authenticated, so a bucket keyed by it would let anyone
+// exhaust another user's limit.
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := routeClass(r.Method, router.Pattern(r))
+		limit := s.clientLimits.limits[class]
+		if class == "" || limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := s.clientLimits.clientIP(r)
+		result := s.clientLimits.take(class+"|"+ip, limit)
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(s.clientLimits.window.Seconds())))
+		h.Set("RateLimit-Limit", strconv.Itoa(result.limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(result.remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+
+		if !result.allowed {
+			httpRateLimited.Inc(class)
+			logging.FromContext(r.Context()).Warn("rate limit exceeded", "class", class, "client_ip", ip)
+
+			retryAfter := ceilSeconds(result.retryAfter)
+			h.Set("Retry-After", strconv.Itoa(retryAfter))
+			s.sendError(w, http.StatusTooManyRequests, models.ErrCodeRateLimited, fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeClass maps a matched route to its limit class. Health checks are
+// exempt so probes never get throttled. Metrics count as reads, which a
+// scraper stays well under, so its token can't be guessed at full speed.
+// Unmatched paths count as reads so scanning for endpoints is limited too.
+func routeClass(method, pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, "/api/v1/health"):
+		return ""
+	case method != http.MethodGet && method != http.MethodHead:
+		return routeClassWrite
+	case strings.HasPrefix(pattern, "/api/v1/search"), strings.HasPrefix(pattern, "/api/v1/trending"):
+		return routeClassSearch
+	default:
+		return routeClassRead
+	}
+}
+
+// clientIP returns the address of the caller. Without a trusted proxy in
+// front, X-Forwarded-For comes from the client and is ignored. Behind one,
+// entries are read from the right, where proxies append them, skipping
+// the trusted proxies' own addresses; the first other entry is the client.
+// With no trusted proxy ranges configured only the last entry is taken, as
+// appended by the single proxy in front, such as Railway's.
+func (l *clientLimiter) clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !l.trustProxy || (len(l.proxies) > 0 && !l.trusted(peer)) {
+		return peer
+	}
+
+	parts := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(parts[i])
+		if ip == "" {
+			break
+		}
+		if len(l.proxies) == 0 || !l.trusted(ip) {
+			return ip
+		}
+		peer = ip
+	}
+	return peer
+}
+
+func (l *clientLimiter) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	for _, proxy := range l.proxies {
+		if ip != nil && proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProxyCIDRs parses trusted proxy ranges, taking a bare address as a
+// range of one. Invalid entries are logged and skipped.
+func parseProxyCIDRs(values []string) []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		_, proxy, err := net.ParseCIDR(value)
+		if err != nil {
+			slog.Warn("ignoring invalid trusted proxy", "value", value, "error", err)
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
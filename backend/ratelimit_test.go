@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"binge-base/config"
+	"binge-base/router"
+)
+
+// testLimiter returns a limiter on a clock the test moves by hand
+func testLimiter(cfg *config.Config) (*clientLimiter, *time.Time) {
+	l := newClientLimiter(cfg)
+	now := time.Unix(1700000000, 0)
+	l.lastSweep = now
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestClientLimiterRefill(t *testing.T) {
+	l, now := testLimiter(&config.Config{RateLimitWindow: 10})
+	key := "read|192.0.2.1"
+
+	for i := 0; i < 2; i++ {
+		if r := l.take(key, 2); !r.allowed || r.remaining != 1-i {
+			t.Fatalf("take %d: allowed %v remaining %d", i, r.allowed, r.remaining)
+		}
+	}
+	r := l.take(key, 2)
+	if r.allowed || r.retryAfter != 5*time.Second || r.reset != 10*time.Second {
+		t.Fatalf("empty bucket: allowed %v retry after %v reset %v", r.allowed, r.retryAfter, r.reset)
+	}
+
+	*now = now.Add(4 * time.Second)
+	if r := l.take(key, 2); r.allowed {
+		t.Fatal("bucket refilled early")
+	}
+	*now = now.Add(time.Second)
+	if r := l.take(key, 2); !r.allowed || r.remaining != 0 {
+		t.Fatalf("after refill: allowed %v remaining %d", r.allowed, r.remaining)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		trust   bool
+		proxies []string
+		peer    string
+		fwd     string
+		want    string
+	}{
+		{"no proxy", false, nil, "192.0.2.1", "198.51.100.7", "192.0.2.1"},
+		{"single proxy", true, nil, "10.0.0.2", "203.0.113.9, 198.51.100.7", "198.51.100.7"},
+		{"no header", true, nil, "10.0.0.2", "", "10.0.0.2"},
+		{"untrusted peer", true, []string{"10.0.0.0/8"}, "192.0.2.1", "198.51.100.7", "192.0.2.1"},
+		{"trusted hops", true, []string{"10.0.0.0/8", "172.16.0.5"}, "10.0.0.2", "203.0.113.9, 198.51.100.7, 172.16.0.5", "198.51.100.7"},
+		{"all trusted", true, []string{"10.0.0.0/8"}, "10.0.0.2", "10.1.1.1", "10.1.1.1"},
+	} {
+		l := newClientLimiter(&config.Config{RateLimitWindow: 60, RateLimitTrustProxy: tc.trust, RateLimitProxyCIDRs: tc.proxies})
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tc.peer + ":1234"
+		if tc.fwd != "" {
+			r.Header.Set("X-Forwarded-For", tc.fwd)
+		}
+		if got := l.clientIP(r); got != tc.want {
+			t.Errorf("%s: %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	cfg := &config.Config{RateLimitWindow: 60, RateLimitWrite: 1, RateLimitRead: 1, MaxBodyBytes: 1024}
+	limiter, _ := testLimiter(cfg)
+	s := &Server{config: cfg, clientLimits: limiter}
+
+	rt := router.New()
+	rt.Use(s.rateLimitMiddleware)
+	rt.Post("/api/v1/watchlist", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	})
+	rt.Get("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	post := func(ip, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/api/v1/watchlist", strings.NewReader(body))
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		return w
+	}
+
+	body := `{"user_id":"alice","content_id":550}`
+	w := post("192.0.2.1", body)
+	if w.Code != http.StatusOK || w.Body.String() != body {
+		t.Fatalf("first write: %d %q, want the body passed through", w.Code, w.Body.String())
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("RateLimit-Remaining %q", w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w = post("192.0.2.1", `{"user_id":"bob"}`)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("second write: %d Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	// Naming a user from another address can't spend that user's limit
+	if w := post("192.0.2.2", body); w.Code != http.StatusOK {
+		t.Errorf("same user from another address: %d", w.Code)
+	}
+
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest("GET", "/api/v1/health", nil)
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("health check %d: %d", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route describes a registered method and path pattern
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+type route struct {
+	method   string
+	pattern  string
+	segments []segment
+	handler  http.Handler
+}
+
+type segment struct {
+	value    string
+	param    bool
+	catchAll bool
+}
+
+// Router dispatches requests by method and path pattern. Patterns are made of
+// slash-separated segments; "{name}" matches a single segment and "{name...}"
+// as the last segment matches the remainder of the path.
+type Router struct {
+	routes     []*route
+	middleware []func(http.Handler) http.Handler
+
+	// NotFound is called when no pattern matches the path
+	NotFound http.Handler
+	// MethodNotAllowed is called when the path matches but the method does not.
+	// The Allow header is already set when it runs.
+	MethodNotAllowed http.Handler
+}
+
+// New creates a router with plain-text 404 and 405 responses
+func New() *Router {
+	return &Router{
+		NotFound: http.NotFoundHandler(),
+		MethodNotAllowed: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}),
+	}
+}
+
+// Handle registers a handler for a method and pattern
+func (rt *Router) Handle(method, pattern string, handler http.Handler) {
+	rt.routes = append(rt.routes, &route{
+		method:   method,
+		pattern:  pattern,
+		segments: parsePattern(pattern),
+		handler:  handler,
+	})
+}
+
+// HandleFunc registers a handler function for a method and pattern
+func (rt *Router) HandleFunc(method, pattern string, handler http.HandlerFunc) {
+	rt.Handle(method, pattern, handler)
+}
+
+func (rt *Router) Get(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodGet, pattern, handler)
+}
+
+func (rt *Router) Post(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodPost, pattern, handler)
+}
+
+func (rt *Router) Put(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodPut, pattern, handler)
+}
+
+func (rt *Router) Delete(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodDelete, pattern, handler)
+}
+
+// Use appends middleware that runs after routing, so it can read the
+// matched pattern with Pattern. It also wraps the NotFound and
+// MethodNotAllowed handlers, for which Pattern returns "".
+func (rt *Router) Use(middleware ...func(http.Handler) http.Handler) {
+	rt.middleware = append(rt.middleware, middleware...)
+}
+
+// Group returns a view of the router that prefixes every pattern
+func (rt *Router) Group(prefix string) *Group {
+	return &Group{router: rt, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+// Routes lists the registered routes in registration order
+func (rt *Router) Routes() []Route {
+	routes := make([]Route, 0, len(rt.routes))
+	for _, r := range rt.routes {
+		routes = append(routes, Route{Method: r.method, Pattern: r.pattern})
+	}
+	return routes
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	parts := splitPath(path)
+
+	// Find the most specific pattern matching the path; static segments
+	// win over parameters so /search/movies beats /search/{type}
+	var best []*route
+	var bestParams map[string]string
+	bestScore := -1
+	for _, candidate := range rt.routes {
+		params, ok := candidate.match(parts)
+		if !ok {
+			continue
+		}
+		score := candidate.score()
+		switch {
+		case score > bestScore:
+			best, bestParams, bestScore = []*route{candidate}, params, score
+		case score == bestScore && candidate.pattern == best[0].pattern:
+			best = append(best, candidate)
+		}
+	}
+
+	if len(best) == 0 {
+		rt.serve(w, r, rt.NotFound, &matched{})
+		return
+	}
+
+	var handler *route
+	for _, candidate := range best {
+		if candidate.method == r.Method {
+			handler = candidate
+			break
+		}
+	}
+	if handler == nil && r.Method == http.MethodHead {
+		for _, candidate := range best {
+			if candidate.method == http.MethodGet {
+				handler = candidate
+				break
+			}
+		}
+	}
+	if handler == nil {
+		w.Header().Set("Allow", strings.Join(allowedMethods(best), ", "))
+		rt.serve(w, r, rt.MethodNotAllowed, &matched{})
+		return
+	}
+
+	rt.serve(w, r, handler.handler, &matched{pattern: handler.pattern, params: bestParams})
+}
+
+func (rt *Router) serve(w http.ResponseWriter, r *http.Request, handler http.Handler, m *matched) {
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		handler = rt.middleware[i](handler)
+	}
+	ctx := context.WithValue(r.Context(), routeKey{}, m)
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Group registers routes below a common path prefix
+type Group struct {
+	router *Router
+	prefix string
+}
+
+func (g *Group) Handle(method, pattern string, handler http.Handler) {
+	g.router.Handle(method, g.prefix+pattern, handler)
+}
+
+func (g *Group) HandleFunc(method, pattern string, handler http.HandlerFunc) {
+	g.router.Handle(method, g.prefix+pattern, handler)
+}
+
+func (g *Group) Get(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodGet, pattern, handler)
+}
+
+func (g *Group) Post(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPost, pattern, handler)
+}
+
+func (g *Group) Put(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPut, pattern, handler)
+}
+
+func (g *Group) Delete(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodDelete, pattern, handler)
+}
+
+// Group returns a nested group below this group's prefix
+func (g *Group) Group(prefix string) *Group {
+	return &Group{router: g.router, prefix: g.prefix + strings.TrimSuffix(prefix, "/")}
+}
+
+type routeKey struct{}
+
+type matched struct {
+	pattern string
+	params  map[string]string
+}
+
+// Param returns the value of a named path parameter, or "" if absent
+func Param(r *http.Request, name string) string {
+	if m, ok := r.Context().Value(routeKey{}).(*matched); ok {
+		return m.params[name]
+	}
+	return ""
+}
+
+// IntParam returns a named path parameter parsed as a positive integer
+func IntParam(r *http.Request, name string) (int, error) {
+	value, err := strconv.Atoi(Param(r, name))
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 {
+		return 0, strconv.ErrRange
+	}
+	return value, nil
+}
+
+// Pattern returns the pattern of the route that matched the request
+func Pattern(r *http.Request) string {
+	if m, ok := r.Context().Value(routeKey{}).(*matched); ok {
+		return m.pattern
+	}
+	return ""
+}
+
+func (rt *route) match(parts []string) (map[string]string, bool) {
+	var params map[string]string
+	for i, seg := range rt.segments {
+		if seg.catchAll {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[seg.value] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		if seg.param {
+			if parts[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[seg.value] = parts[i]
+			continue
+		}
+		if seg.value != parts[i] {
+			return nil, false
+		}
+	}
+	return params, len(parts) == len(rt.segments)
+}
+
+// score ranks patterns by specificity: earlier static segments weigh more
+func (rt *route) score() int {
+	score := 0
+	for _, seg := range rt.segments {
+		score <<= 2
+		switch {
+		case seg.catchAll:
+		case seg.param:
+			score++
+		default:
+			score += 2
+		}
+	}
+	return score
+}
+
+func parsePattern(pattern string) []segment {
+	parts := splitPath(pattern)
+	segments := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name := part[1 : len(part)-1]
+			if strings.HasSuffix(name, "...") {
+				segments = append(segments, segment{value: strings.TrimSuffix(name, "..."), param: true, catchAll: true})
+				continue
+			}
+			segments = append(segments, segment{value: name, param: true})
+			continue
+		}
+		segments = append(segments, segment{value: part})
+	}
+	return segments
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// allowedMethods lists the methods registered for a path, with HEAD for
+// GET routes since they answer it too
+func allowedMethods(routes []*route) []string {
+	seen := map[string]bool{}
+	for _, r := range routes {
+		seen[r.method] = true
+		if r.method == http.MethodGet {
+			seen[http.MethodHead] = true
+		}
+	}
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
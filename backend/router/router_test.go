@@ -0,0 +1,143 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echo responds with the matched pattern and the named params
+func echo(names ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		out := []string{r.Method, Pattern(r)}
+		for _, name := range names {
+			out = append(out, name+"="+Param(r, name))
+		}
+		w.Write([]byte(strings.Join(out, " ")))
+	}
+}
+
+func newTestRouter() *Router {
+	rt := New()
+	rt.Get("/movie/{id}", echo("id"))
+	rt.Put("/movie/{id}", echo("id"))
+	rt.Get("/search/{type}", echo("type"))
+	rt.Get("/search/movies", echo())
+	rt.Get("/tv/{id}/season/{season}", echo("id", "season"))
+	rt.Get("/static/{path...}", echo("path"))
+	api := rt.Group("/api/v1/")
+	api.Post("/watchlist", echo())
+	api.Group("/genres").Get("/{id}/{type}", echo("id", "type"))
+	return rt
+}
+
+func TestRouting(t *testing.T) {
+	rt := newTestRouter()
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+		allow        string
+	}{
+		{"GET", "/movie/42", 200, "GET /movie/{id} id=42", ""},
+		{"GET", "/movie/42/", 200, "GET /movie/{id} id=42", ""},
+		{"PUT", "/movie/42", 200, "PUT /movie/{id} id=42", ""},
+		{"HEAD", "/movie/42", 200, "HEAD /movie/{id} id=42", ""},
+		{"GET", "/search/movies", 200, "GET /search/movies", ""},
+		{"GET", "/search/tv", 200, "GET /search/{type} type=tv", ""},
+		{"GET", "/tv/1/season/0", 200, "GET /tv/{id}/season/{season} id=1 season=0", ""},
+		{"GET", "/static/assets/app.js", 200, "GET /static/{path...} path=assets/app.js", ""},
+		{"GET", "/static", 200, "GET /static/{path...} path=", ""},
+		{"POST", "/api/v1/watchlist", 200, "POST /api/v1/watchlist", ""},
+		{"GET", "/api/v1/genres/28/movie", 200, "GET /api/v1/genres/{id}/{type} id=28 type=movie", ""},
+		{"GET", "/movie", 404, "", ""},
+		{"GET", "/movie/42/extra", 404, "", ""},
+		{"GET", "/movie//", 404, "", ""},
+		{"GET", "/unknown", 404, "", ""},
+		{"DELETE", "/movie/42", 405, "", "GET, HEAD, PUT"},
+		{"GET", "/api/v1/watchlist", 405, "", "POST"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, w.Code, tt.status)
+			continue
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s %s: body %q, want %q", tt.method, tt.path, w.Body.String(), tt.body)
+		}
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Allow %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+}
+
+func TestMiddlewareSeesMatchedPattern(t *testing.T) {
+	rt := newTestRouter()
+	var seen []string
+	rt.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = append(seen, "outer:"+Pattern(r))
+			next.ServeHTTP(w, r)
+		})
+	}, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = append(seen, "inner")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/movie/1", nil))
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", nil))
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/movie/1", nil))
+
+	want := "outer:/movie/{id} inner outer: inner outer: inner"
+	if got := strings.Join(seen, " "); got != want {
+		t.Errorf("middleware calls %q, want %q", got, want)
+	}
+}
+
+func TestIntParam(t *testing.T) {
+	tests := []struct {
+		path  string
+		want  int
+		valid bool
+	}{
+		{"/movie/42", 42, true},
+		{"/movie/0", 0, false},
+		{"/movie/-3", 0, false},
+		{"/movie/abc", 0, false},
+		{"/movie/1e3", 0, false},
+		{"/movie/99999999999999999999", 0, false},
+	}
+	for _, tt := range tests {
+		rt := New()
+		var got int
+		var err error
+		rt.Get("/movie/{id}", func(w http.ResponseWriter, r *http.Request) {
+			got, err = IntParam(r, "id")
+		})
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("%s: got %d, %v", tt.path, got, err)
+		}
+	}
+
+	// Parameters the route doesn't define are errors, not zero
+	r := httptest.NewRequest("GET", "/movie/1", nil)
+	if _, err := IntParam(r, "id"); err == nil {
+		t.Error("expected an error for a request that was not routed")
+	}
+}
+
+func TestRoutesListsRegistrationOrder(t *testing.T) {
+	routes := newTestRouter().Routes()
+	if len(routes) != 8 {
+		t.Fatalf("got %d routes", len(routes))
+	}
+	if routes[0] != (Route{Method: "GET", Pattern: "/movie/{id}"}) || routes[7] != (Route{Method: "GET", Pattern: "/api/v1/genres/{id}/{type}"}) {
+		t.Errorf("unexpected routes %v", routes)
+	}
+}
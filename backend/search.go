@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"binge-base/models"
+	"binge-base/services"
+)
+
+// searchWindowPages is how many TMDB pages per media type are considered
+// when a query's results have to be filtered or sorted on our side
+const searchWindowPages = 2
+
+var searchSorts = []string{"relevance", "popularity", "rating", "newest", "oldest", "title"}
+
+// searchFilters narrow and order search results. Without a query TMDB's
+// discover endpoints apply all of them; with one only year and adult
+// content are passed to TMDB and the rest is applied to a window of top
+// results.
+type searchFilters struct {
+	opts      services.SearchOptions
+	genres    []int
+	minRating float64
+	language  string
+	sort      string
+}
+
+func parseSearchFilters(r *http.Request) (searchFilters, error) {
+	q := r.URL.Query()
+	f := searchFilters{sort: q.Get("sort")}
+	if v := q.Get("year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 1870 || year > 2100 {
+			return f, errors.New("year must be a four-digit year")
+		}
+		f.opts.Year = year
+	}
+	if v := q.Get("genre"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || id <= 0 {
+				return f, errors.New("genre must be a comma-separated list of genre IDs")
+			}
+			f.genres = append(f.genres, id)
+		}
+	}
+	if v := q.Get("min_rating"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || rating < 0 || rating > 10 {
+			return f, errors.New("min_rating must be between 0 and 10")
+		}
+		f.minRating = rating
+	}
+	if v := q.Get("language"); v != "" {
+		if len(v) != 2 {
+			return f, errors.New("language must be an ISO 639-1 code such as en or ko")
+		}
+		f.language = strings.ToLower(v)
+	}
+	if v := q.Get("include_adult"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("include_adult must be true or false")
+		}
+		f.opts.IncludeAdult = include
+	}
+	if f.sort != "" && !contains(searchSorts, f.sort) {
+		return f, errors.New("sort must be one of " + strings.Join(searchSorts, ", "))
+	}
+	return f, nil
+}
+
+// titlesOnly reports whether the filters describe titles, which no person
+// matches
+func (f searchFilters) titlesOnly() bool {
+	return f.opts.Year > 0 || len(f.genres) > 0 || f.minRating > 0 || f.language != ""
+}
+
+// serverSide reports whether the filters need more than TMDB can do itself
+func (f searchFilters) serverSide() bool {
+	return len(f.genres) > 0 || f.minRating > 0 || f.language != "" || f.sorted()
+}
+
+func (f searchFilters) sorted() bool {
+	return f.sort != "" && f.sort != "relevance"
+}
+
+func (f searchFilters) discoverOptions() services.DiscoverOptions {
+	return services.DiscoverOptions{SearchOptions: f.opts, Genres: f.genres, MinRating: f.minRating, Language: f.language, Sort: f.sort}
+}
+
+// match applies every server-side filter except genre, which is kept
+// separate so the genre facet can count across all genres
+func (f searchFilters) match(item models.ResultItem) bool {
+	if f.minRating > 0 && item.VoteAverage < f.minRating {
+		return false
+	}
+	if f.language != "" && item.OriginalLanguage != f.language {
+		return false
+	}
+	return true
+}
+
+func (f searchFilters) matchGenre(item models.ResultItem) bool {
+	if len(f.genres) == 0 {
+		return true
+	}
+	for _, id := range item.GenreIDs {
+		for _, want := range f.genres {
+			if id == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Server) searchMedia(w http.ResponseWriter, r *http.Request, mediaTypes ...string) {
+	query := r.URL.Query().Get("query")
+	filters, err := parseSearchFilters(r)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	// Without a query, filtered or sorted title searches browse the catalogue
+	peopleOnly := len(mediaTypes) == 1 && mediaTypes[0] == models.MediaTypePerson
+	discover := query == "" && !peopleOnly && (filters.titlesOnly() || filters.sorted())
+	if query == "" && !discover {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Query parameter is required")
+		return
+	}
+	if filters.opts.IncludeAdult {
+		userID := r.URL.Query().Get("user_id")
+		if userID == "" {
+			userID = "default_user"
+		}
+		settings, err := s.db.GetUserSettings(userID)
+		if err != nil {
+			s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get user settings")
+			return
+		}
+		if !settings.IncludeAdult {
+			s.sendError(w, http.StatusForbidden, models.ErrCodeForbidden, "Adult titles must be enabled in the user's settings first")
+			return
+		}
+	}
+
+	var sources []resultSource
+	for _, mediaType := range mediaTypes {
+		// A mixed search drops people rather than list them unfiltered
+		if mediaType == models.MediaTypePerson && len(mediaTypes) > 1 && (discover || filters.titlesOnly()) {
+			continue
+		}
+		if discover {
+			sources = append(sources, s.discoverSource(mediaType, filters.discoverOptions()))
+			continue
+		}
+		sources = append(sources, s.searchSource(query, mediaType, filters.opts))
+	}
+	if discover || !filters.serverSide() {
+		s.sendSearchPage(w, r, sources, filters)
+		return
+	}
+	s.sendFilteredPage(w, r, sources, filters)
+}
+
+// sendSearchPage answers with the requested page of the merged sources
+// and facet counts over that page. The sources are already filtered and
+// sorted; sorting the page again orders the movies and shows interleaved
+// on it.
+func (s *Server) sendSearchPage(w http.ResponseWriter, r *http.Request, sources []resultSource, filters searchFilters) {
+	results, pagination, err := mergedPage(r.Context(), sources, pageParam(r), perPageParam(r))
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "", "Failed to search")
+		return
+	}
+	sortSearchResults(results, filters.sort)
+	_, genreCounts, decadeCounts := filters.apply(results)
+	s.sendJSON(w, http.StatusOK, models.APIResponse{
+		Success:    true,
+		Data:       parseFields(r).items(results),
+		Pagination: &pagination,
+		Facets:     s.searchFacets(r.Context(), genreCounts, decadeCounts),
+	})
+}
+
+func (s *Server) searchSource(query, mediaType string, opts services.SearchOptions) resultSource {
+	return resultSource{mediaType: mediaType, fetch: func(ctx context.Context, page int) (*models.SearchResult, error) {
+		switch mediaType {
+		case models.MediaTypeMovie:
+			return s.tmdbService.SearchMovies(ctx, query, page, opts)
+		case models.MediaTypePerson:
+			return s.tmdbService.SearchPeople(ctx, query, page, opts)
+		}
+		return s.tmdbService.SearchTVShows(ctx, query, page, opts)
+	}}
+}
+
+func (s *Server) discoverSource(mediaType string, opts services.DiscoverOptions) resultSource {
+	return resultSource{mediaType: mediaType, fetch: func(ctx context.Context, page int) (*models.SearchResult, error) {
+		return s.tmdbService.Discover(ctx, mediaType, page, opts)
+	}}
+}
+
+// apply returns the items that pass the filters, with genre counts over
+// the items that pass every filter but genre and decade counts over the
+// matches
+func (f searchFilters) apply(items []models.ResultItem) (matched []models.ResultItem, genreCounts, decadeCounts map[int]int) {
+	genreCounts, decadeCounts = map[int]int{}, map[int]int{}
+	for _, item := range items {
+		if !f.match(item) {
+			continue
+		}
+		for _, id := range item.GenreIDs {
+			genreCounts[id]++
+		}
+		if !f.matchGenre(item) {
+			continue
+		}
+		if year := item.Year(); year > 0 {
+			decadeCounts[year/10*10]++
+		}
+		matched = append(matched, item)
+	}
+	return matched, genreCounts, decadeCounts
+}
+
+// sendFilteredPage filters and sorts the top results of every source, then
+// answers with the requested page and facet counts over the filtered set.
+// The pagination is marked truncated when results beyond the window exist.
+func (s *Server) sendFilteredPage(w http.ResponseWriter, r *http.Request, sources []resultSource, filters searchFilters) {
+	window := searchWindowPages * tmdbPageSize * len(sources)
+	candidates, all, err := mergedPage(r.Context(), sources, 1, window)
+	if err != nil {
+		s.sendUpstreamError(w, r, err, "", "Failed to search")
+		return
+	}
+
+	matched, genreCounts, decadeCounts := filters.apply(candidates)
+	sortSearchResults(matched, filters.sort)
+
+	page, perPage := pageParam(r), perPageParam(r)
+	results := []models.ResultItem{}
+	for i := (page - 1) * perPage; i < page*perPage && i < len(matched); i++ {
+		results = append(results, matched[i])
+	}
+	pagination := models.Pagination{
+		Page:         page,
+		PerPage:      perPage,
+		TotalResults: len(matched),
+		TotalPages:   (len(matched) + perPage - 1) / perPage,
+		Truncated:    all.TotalResults > window,
+	}
+	s.sendJSON(w, http.StatusOK, models.APIResponse{
+		Success:    true,
+		Data:       parseFields(r).items(results),
+		Pagination: &pagination,
+		Facets:     s.searchFacets(r.Context(), genreCounts, decadeCounts),
+	})
+}
+
+func (s *Server) searchFacets(ctx context.Context, genreCounts, decadeCounts map[int]int) *models.SearchFacets {
+	names := map[int]string{}
+	if genres, err := s.genreCatalog(ctx); err == nil {
+		for _, genre := range genres {
+			names[genre.ID] = genre.Name
+		}
+	}
+
+	facets := &models.SearchFacets{Genres: []models.FacetCount{}, Decades: []models.FacetCount{}}
+	for id, count := range genreCounts {
+		facets.Genres = append(facets.Genres, models.FacetCount{Value: strconv.Itoa(id), Label: names[id], Count: count})
+	}
+	for decade, count := range decadeCounts {
+		facets.Decades = append(facets.Decades, models.FacetCount{Value: strconv.Itoa(decade), Label: strconv.Itoa(decade) + "s", Count: count})
+	}
+	sortFacets(facets.Genres)
+	sort.Slice(facets.Decades, func(i, j int) bool { return facets.Decades[i].Value > facets.Decades[j].Value })
+	return facets
+}
+
+func sortFacets(counts []models.FacetCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Label != counts[j].Label {
+			return counts[i].Label < counts[j].Label
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
+
+// sortSearchResults orders results in place; relevance keeps TMDB's order.
+// Titles without a date sort last either way.
+func sortSearchResults(items []models.ResultItem, order string) {
+	var less func(a, b models.ResultItem) bool
+	switch order {
+	case "popularity":
+		less = func(a, b models.ResultItem) bool { return a.Popularity > b.Popularity }
+	case "rating":
+		less = func(a, b models.ResultItem) bool {
+			if a.VoteAverage != b.VoteAverage {
+				return a.VoteAverage > b.VoteAverage
+			}
+			return a.VoteCount > b.VoteCount
+		}
+	case "newest", "oldest":
+		less = func(a, b models.ResultItem) bool {
+			da, db := a.ReleaseDate, b.ReleaseDate
+			if da == "" || db == "" {
+				return db == "" && da != ""
+			}
+			if order == "newest" {
+				return da > db
+			}
+			return da < db
+		}
+	case "title":
+		less = func(a, b models.ResultItem) bool {
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		}
+	default:
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool { return less(items[i], items[j]) })
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
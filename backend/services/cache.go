@@ -0,0 +1,70 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const maxCacheEntries = 2000
+
+// responseCache is an in-memory TTL cache of raw upstream response bodies
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *responseCache) get(key string) ([]byte, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *responseCache) set(key string, body []byte) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		c.evict()
+	}
+	c.entries[key] = cacheEntry{body: body, expires: time.Now().Add(c.ttl)}
+}
+
+// evict drops expired entries, then arbitrary ones until there is room.
+// Callers must hold c.mu.
+func (c *responseCache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < maxCacheEntries {
+			break
+		}
+		delete(c.entries, key)
+	}
+}
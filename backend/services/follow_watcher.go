@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"binge-base/database"
+	"binge-base/logging"
+	"binge-base/models"
+)
+
+// companyPages caps the pages of upcoming titles fetched per company and
+// media type on each scan
+const companyPages = 10
+
+// FollowWatcher periodically diffs the credits of followed people and
+// companies against what it has seen before, recording newly announced
+// titles for the "new from people you follow" feed.
+type FollowWatcher struct {
+	db          *database.Database
+	tmdbService *TMDBService
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	// mu keeps the loop and Baseline from scanning at the same time
+	mu sync.Mutex
+}
+
+func NewFollowWatcher(db *database.Database, tmdbService *TMDBService) *FollowWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &FollowWatcher{
+		db:          db,
+		tmdbService: tmdbService,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start runs a scan immediately and then once per interval
+func (w *FollowWatcher) Start(interval time.Duration) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			w.scan()
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the scan loop, cancelling the upstream calls of a running
+// scan, and waits for it to return
+func (w *FollowWatcher) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *FollowWatcher) scan() {
+	// Tag the scan's upstream calls so they can be told apart in the logs
+	ctx := logging.WithRequestID(w.ctx, "follow-scan-"+strconv.FormatInt(time.Now().Unix(), 10))
+
+	targets, err := w.db.GetFollowTargets()
+	if err != nil {
+		slog.Error("failed to list followed people and companies", "error", err)
+		return
+	}
+
+	for _, target := range targets {
+		select {
+		case <-w.ctx.Done():
+			return
+		default:
+		}
+
+		if err := w.scanTarget(ctx, target); err != nil {
+			slog.Warn("failed to scan followed titles", "target_type", target.TargetType, "target_id", target.TargetID, "error", err)
+		}
+	}
+}
+
+// Baseline records what a newly followed target is already credited with,
+// so anything announced before the next scan is recognised as new. Targets
+// that were scanned before are left to the loop.
+func (w *FollowWatcher) Baseline(ctx context.Context, target models.FollowTarget) error {
+	if target.CheckedAt != nil {
+		return nil
+	}
+	return w.scanTarget(ctx, target)
+}
+
+func (w *FollowWatcher) scanTarget(ctx context.Context, target models.FollowTarget) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	titles, err := w.titles(ctx, target)
+	if err != nil {
+		return err
+	}
+	known, err := w.db.GetFollowedTitles(target.TargetType, target.TargetID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(known))
+	for _, title := range known {
+		seen[title.ContentType+":"+strconv.Itoa(title.ContentID)] = true
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	var baseline, announced []models.FollowedTitle
+	for _, title := range titles {
+		key := title.ContentType + ":" + strconv.Itoa(title.ContentID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		// Everything credited before the first scan is the baseline, and
+		// newly credited titles that are already out are back catalogue.
+		// A target baselined since this scan listed it has titles stored.
+		if (target.CheckedAt == nil && len(known) == 0) || (title.ReleaseDate != "" && title.ReleaseDate < today) {
+			baseline = append(baseline, title)
+		} else {
+			announced = append(announced, title)
+		}
+	}
+	if err := w.db.RecordFollowScan(target, baseline, announced); err != nil {
+		return err
+	}
+	if len(announced) > 0 {
+		slog.Info("found newly announced titles", "target_type", target.TargetType, "target_id", target.TargetID, "name", target.Name, "titles", len(announced))
+	}
+	return nil
+}
+
+// titles fetches the current movie and TV credits of a followed target
+func (w *FollowWatcher) titles(ctx context.Context, target models.FollowTarget) ([]models.FollowedTitle, error) {
+	var titles []models.FollowedTitle
+	switch target.TargetType {
+	case models.FollowPerson:
+		credits, err := w.tmdbService.GetPersonCredits(ctx, target.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, credit := range append(credits.Cast, credits.Crew...) {
+			if credit.Adult {
+				continue
+			}
+			role := credit.Character
+			if role == "" {
+				role = credit.Job
+			}
+			titles = append(titles, followedTitle(credit.ResultItem, role))
+		}
+	case models.FollowCompany:
+		// A company's back catalogue is too long to page through, and a
+		// title it adds that is already out wouldn't be announced anyway,
+		// so only titles dated from today on are fetched
+		since := time.Now().UTC().Format("2006-01-02")
+		for _, mediaType := range []string{models.MediaTypeMovie, models.MediaTypeTV} {
+			for page := 1; page <= companyPages; page++ {
+				result, err := w.tmdbService.GetCompanyTitles(ctx, mediaType, target.TargetID, since, page)
+				if err != nil {
+					return nil, err
+				}
+				for _, item := range result.Results {
+					titles = append(titles, followedTitle(item, ""))
+				}
+				if page >= result.TotalPages {
+					break
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown follow target type %q", target.TargetType)
+	}
+	return titles, nil
+}
+
+func followedTitle(item models.ResultItem, role string) models.FollowedTitle {
+	return models.FollowedTitle{
+		ContentID:   item.ID,
+		ContentType: item.MediaType,
+		Title:       item.Title,
+		ReleaseDate: item.ReleaseDate,
+		PosterPath:  item.PosterPath,
+		Role:        role,
+	}
+}
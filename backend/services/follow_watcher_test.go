@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"binge-base/config"
+	"binge-base/database"
+	"binge-base/models"
+)
+
+func TestFollowWatcherBaselinesAndPagesCompanies(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+	var mu sync.Mutex
+	movies := 5 // upcoming movies, two per page
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path == "/discover/movie" && q.Get("primary_release_date.gte") != today {
+			t.Errorf("movies requested from %q, want %s", q.Get("primary_release_date.gte"), today)
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		result := models.SearchResult{Page: page, Results: []models.ResultItem{}}
+		if r.URL.Path == "/discover/movie" {
+			mu.Lock()
+			result.TotalResults, result.TotalPages = movies, (movies+1)/2
+			for id := page*2 - 1; id <= page*2 && id <= movies; id++ {
+				result.Results = append(result.Results, models.ResultItem{ID: id, Title: fmt.Sprint("Movie ", id), ReleaseDate: "2099-01-01"})
+			}
+			mu.Unlock()
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	db, err := database.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tmdb := NewTMDBService(&config.Config{TMDBRateLimit: 1000})
+	tmdb.baseURL = server.URL
+	w := NewFollowWatcher(db, tmdb)
+	defer w.Stop()
+
+	follow, err := db.AddFollow("alice", models.FollowTarget{TargetType: models.FollowCompany, TargetID: 420, Name: "Studio"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Baseline(context.Background(), follow.FollowTarget); err != nil {
+		t.Fatal(err)
+	}
+	known, _ := db.GetFollowedTitles(models.FollowCompany, 420)
+	if len(known) != 5 {
+		t.Fatalf("baseline has %d titles, want all 5 pages' worth", len(known))
+	}
+
+	// Announced after the follow, so it is new even before the next scan
+	mu.Lock()
+	movies = 6
+	mu.Unlock()
+	w.scan()
+	releases, _ := db.GetFollowedReleases("alice", 50)
+	if len(releases) != 1 || releases[0].ContentID != 6 {
+		t.Errorf("releases %+v, want only movie 6", releases)
+	}
+}
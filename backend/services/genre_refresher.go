@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"binge-base/database"
+	"binge-base/logging"
+)
+
+// GenreRefresher periodically copies TMDB's movie and TV genre lists into
+// the genres table, so the catalog is served without an upstream call.
+type GenreRefresher struct {
+	db          *database.Database
+	tmdbService *TMDBService
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewGenreRefresher(db *database.Database, tmdbService *TMDBService) *GenreRefresher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GenreRefresher{
+		db:          db,
+		tmdbService: tmdbService,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start refreshes immediately and then once per interval
+func (g *GenreRefresher) Start(interval time.Duration) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			g.refresh()
+			select {
+			case <-g.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the refresh loop, cancelling the upstream calls of a running
+// refresh, and waits for it to return
+func (g *GenreRefresher) Stop() {
+	g.cancel()
+	g.wg.Wait()
+}
+
+func (g *GenreRefresher) refresh() {
+	if !g.tmdbService.Configured() {
+		return
+	}
+	// The stored catalog is in DefaultLanguage, the locale of a context
+	// without one
+	ctx := logging.WithRequestID(g.ctx, "genre-refresh-"+strconv.FormatInt(time.Now().Unix(), 10))
+
+	genres, err := g.tmdbService.GetGenres(ctx)
+	if err != nil {
+		slog.Warn("failed to fetch genres from TMDB", "error", err)
+		return
+	}
+	if err := g.db.ReplaceGenres(genres); err != nil {
+		slog.Error("failed to store genres", "error", err)
+		return
+	}
+	slog.Info("refreshed genre catalog", "genres", len(genres))
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultLanguage is used when a request names no language, and is the
+// fallback for fields TMDB has no translation of
+const DefaultLanguage = "en-US"
+
+// Locale selects the language of titles, overviews and genre names, and
+// the country used for release dates and regional search results
+type Locale struct {
+	// Language is an ISO 639-1 code, optionally with a country, such as
+	// "fr" or "es-MX"
+	Language string
+	// Region is an ISO 3166-1 country code; empty means worldwide
+	Region string
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context carrying the locale TMDB calls should use
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom returns the locale stored in the context, defaulting to
+// DefaultLanguage for background work that has no request
+func LocaleFrom(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(localeKey{}).(Locale); ok && locale.Language != "" {
+		return locale
+	}
+	return Locale{Language: DefaultLanguage}
+}
+
+// English reports whether the locale's language is English, in which case
+// there is nothing to fall back to
+func (l Locale) English() bool {
+	return l.Language == "en" || strings.HasPrefix(l.Language, "en-")
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket that makes callers wait for capacity
+// instead of failing, keeping us under the upstream API's quota
+type rateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+// newRateLimiter allows limit calls per interval, with bursts up to limit.
+// A non-positive limit disables limiting.
+func newRateLimiter(limit int, interval time.Duration) *rateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		capacity: float64(limit),
+		tokens:   float64(limit),
+		rate:     float64(limit) / interval.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a token is available and returns how long it waited
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.capacity {
+		l.tokens = l.capacity
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		// Hand the reserved token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return delay, ctx.Err()
+	}
+}
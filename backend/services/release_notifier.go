@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"binge-base/database"
+	"binge-base/logging"
+)
+
+// ReleaseNotifier periodically scans unwatched watchlist items and fires
+// release.upcoming webhooks for titles releasing within the notice window.
+type ReleaseNotifier struct {
+	db          *database.Database
+	tmdbService *TMDBService
+	webhooks    *WebhookService
+	window      time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewReleaseNotifier(db *database.Database, tmdbService *TMDBService, webhooks *WebhookService, noticeDays int) *ReleaseNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReleaseNotifier{
+		db:          db,
+		tmdbService: tmdbService,
+		webhooks:    webhooks,
+		window:      time.Duration(noticeDays) * 24 * time.Hour,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start runs a scan immediately and then once per interval
+func (n *ReleaseNotifier) Start(interval time.Duration) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n.scan()
+			select {
+			case <-n.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the scan loop, cancelling the upstream calls of a running
+// scan, and waits for it to return
+func (n *ReleaseNotifier) Stop() {
+	n.cancel()
+	n.wg.Wait()
+}
+
+func (n *ReleaseNotifier) scan() {
+	// Tag the scan's upstream calls so they can be told apart in the logs
+	ctx := logging.WithRequestID(n.ctx, "release-scan-"+strconv.FormatInt(time.Now().Unix(), 10))
+
+	items, err := n.db.GetUnwatchedWatchlist()
+	if err != nil {
+		slog.Error("failed to scan watchlist for releases", "error", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	subscribed := make(map[string]bool)
+	for _, item := range items {
+		select {
+		case <-n.ctx.Done():
+			return
+		default:
+		}
+
+		// Skip users without a release.upcoming subscription so we don't
+		// spend TMDB calls or record notices nobody received
+		hasHooks, seen := subscribed[item.UserID]
+		if !seen {
+			webhooks, err := n.db.GetWebhooksForEvent(item.UserID, EventReleaseUpcoming)
+			hasHooks = err == nil && len(webhooks) > 0
+			subscribed[item.UserID] = hasHooks
+		}
+		if !hasHooks {
+			continue
+		}
+
+		var releaseDate, title string
+		data := map[string]interface{}{
+			"content_id":   item.ContentID,
+			"content_type": item.ContentType,
+		}
+		switch item.ContentType {
+		case "movie":
+			movie, err := n.tmdbService.GetMovieDetails(ctx, item.ContentID)
+			if err != nil {
+				continue
+			}
+			releaseDate, title = movie.ReleaseDate, movie.Title
+			data["poster_path"] = movie.PosterPath
+		case "tv":
+			show, err := n.tmdbService.GetTVDetails(ctx, item.ContentID)
+			if err != nil || show.NextEpisodeToAir == nil {
+				continue
+			}
+			releaseDate, title = show.NextEpisodeToAir.AirDate, show.Name
+			data["poster_path"] = show.PosterPath
+			data["season_number"] = show.NextEpisodeToAir.SeasonNumber
+			data["episode_number"] = show.NextEpisodeToAir.EpisodeNumber
+			data["episode_name"] = show.NextEpisodeToAir.Name
+		default:
+			continue
+		}
+
+		release, err := time.Parse("2006-01-02", releaseDate)
+		if err != nil || release.Before(now.Truncate(24*time.Hour)) || release.After(now.Add(n.window)) {
+			continue
+		}
+
+		first, err := n.db.MarkReleaseNotified(item.UserID, item.ContentID, item.ContentType, releaseDate)
+		if err != nil || !first {
+			continue
+		}
+
+		data["title"] = title
+		data["release_date"] = releaseDate
+		// Forget the notice if it couldn't be queued so the next scan retries
+		// it. Webhooks that did receive it may then get it twice.
+		if err := n.webhooks.Dispatch(item.UserID, EventReleaseUpcoming, data); err != nil {
+			if err := n.db.UnmarkReleaseNotified(item.UserID, item.ContentID, item.ContentType, releaseDate); err != nil {
+				slog.Error("failed to reset release notification", "user", item.UserID, "content_id", item.ContentID, "error", err)
+			}
+		}
+	}
+}
@@ -1,285 +1,847 @@
-package services
-
-import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"strconv"
-	"time"
-
-	"binge-base/config"
-	"binge-base/models"
-)
-
-type TMDBService struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
-}
-
-func NewTMDBService(cfg *config.Config) *TMDBService {
-	return &TMDBService{
-		apiKey:  cfg.TMDBAPIKey,
-		baseURL: "https://api.themoviedb.org/3",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
-}
-
-// SearchMovies searches for movies using TMDB API
-func (s *TMDBService) SearchMovies(query string, page int) (*models.SearchResult, error) {
-	endpoint := fmt.Sprintf("%s/search/movie", s.baseURL)
-
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("query", query)
-	params.Add("page", strconv.Itoa(page))
-	params.Add("include_adult", "false")
-	params.Add("language", "en-US")
-
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to search movies: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var result models.SearchResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &result, nil
-}
-
-// SearchTVShows searches for TV shows using TMDB API
-func (s *TMDBService) SearchTVShows(query string, page int) (*models.SearchResult, error) {
-	endpoint := fmt.Sprintf("%s/search/tv", s.baseURL)
-
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("query", query)
-	params.Add("page", strconv.Itoa(page))
-	params.Add("include_adult", "false")
-	params.Add("language", "en-US")
-
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to search TV shows: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var result models.SearchResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &result, nil
-}
-
-// GetMovieProviders fetches streaming providers for a movie
-func (s *TMDBService) GetMovieProviders(movieID int) (map[string]interface{}, error) {
-	endpoint := fmt.Sprintf("%s/movie/%d/watch/providers", s.baseURL, movieID)
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get movie providers: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode providers: %w", err)
-	}
-	return result, nil
-}
-
-// GetMovieDetails gets detailed information about a movie
-func (s *TMDBService) GetMovieDetails(movieID int) (*models.Movie, error) {
-	endpoint := fmt.Sprintf("%s/movie/%d", s.baseURL, movieID)
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("language", "en-US")
-	params.Add("append_to_response", "credits,videos,images")
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get movie details: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-	var movie models.Movie
-	if err := json.NewDecoder(resp.Body).Decode(&movie); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-	// Fetch providers
-	providers, _ := s.GetMovieProviders(movieID)
-	if providers != nil {
-		movie.Providers = providers["results"]
-	}
-	// Extract YouTube trailer key
-	if videos, ok := movie.Videos["results"].([]interface{}); ok {
-		for _, v := range videos {
-			if video, ok := v.(map[string]interface{}); ok {
-				if video["site"] == "YouTube" && video["type"] == "Trailer" {
-					movie.Trailer = video["key"].(string)
-					break
-				}
-			}
-		}
-	}
-	return &movie, nil
-}
-
-// GetTVDetails gets detailed information about a TV show
-func (s *TMDBService) GetTVDetails(tvID int) (*models.TVShow, error) {
-	endpoint := fmt.Sprintf("%s/tv/%d", s.baseURL, tvID)
-
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("language", "en-US")
-	params.Add("append_to_response", "credits,videos,images")
-
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get TV show details: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var tvShow models.TVShow
-	if err := json.Unmarshal(body, &tvShow); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &tvShow, nil
-}
-
-// GetTrendingMovies gets trending movies
-func (s *TMDBService) GetTrendingMovies(page int) (*models.TrendingResult, error) {
-	endpoint := fmt.Sprintf("%s/trending/movie/week", s.baseURL)
-
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("page", strconv.Itoa(page))
-	params.Add("language", "en-US")
-
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get trending movies: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var result models.TrendingResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &result, nil
-}
-
-// GetTrendingTVShows gets trending TV shows
-func (s *TMDBService) GetTrendingTVShows(page int) (*models.TrendingResult, error) {
-	endpoint := fmt.Sprintf("%s/trending/tv/week", s.baseURL)
-
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("page", strconv.Itoa(page))
-	params.Add("language", "en-US")
-
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get trending TV shows: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var result models.TrendingResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &result, nil
-}
-
-// GetGenres gets movie and TV show genres
-func (s *TMDBService) GetGenres() ([]models.Genre, error) {
-	endpoint := fmt.Sprintf("%s/genre/movie/list", s.baseURL)
-
-	params := url.Values{}
-	params.Add("api_key", s.apiKey)
-	params.Add("language", "en-US")
-
-	resp, err := s.httpClient.Get(fmt.Sprintf("%s?%s", endpoint, params.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get genres: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API error: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var response struct {
-		Genres []models.Genre `json:"genres"`
-	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return response.Genres, nil
-}
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"binge-base/config"
+	"binge-base/metrics"
+	"binge-base/models"
+)
+
+var tmdbCacheRequests = metrics.NewCounterVec("bingebase_tmdb_cache_requests_total",
+	"Lookups in the TMDB response cache by result (hit or miss).", "result")
+
+type TMDBService struct {
+	apiKey  string
+	baseURL string
+	client  *upstreamClient
+	cache   *responseCache
+}
+
+func NewTMDBService(cfg *config.Config) *TMDBService {
+	return &TMDBService{
+		apiKey:  cfg.TMDBAPIKey,
+		baseURL: "https://api.themoviedb.org/3",
+		// TMDB's quota is expressed per 10 seconds
+		client: newUpstreamClient("tmdb", newRateLimiter(cfg.TMDBRateLimit, 10*time.Second)),
+		cache:  newResponseCache(time.Duration(cfg.CacheDuration) * time.Second),
+	}
+}
+
+func init() {
+	metrics.NewGaugeFunc("bingebase_tmdb_cache_hit_ratio",
+		"Share of TMDB response cache lookups served from cache since start.", func() float64 {
+			hits, misses := tmdbCacheRequests.Value("hit"), tmdbCacheRequests.Value("miss")
+			if hits+misses == 0 {
+				return 0
+			}
+			return hits / (hits + misses)
+		})
+}
+
+// fetch returns the body of a successful TMDB GET, serving repeated
+// requests from the response cache
+func (s *TMDBService) fetch(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	// The cache key leaves out the API key
+	key := endpoint + "?" + cacheParams(params)
+	if body, ok := s.cache.get(key); ok {
+		tmdbCacheRequests.Inc("hit")
+		return body, nil
+	}
+	tmdbCacheRequests.Inc("miss")
+
+	resp, err := s.client.get(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamError{Service: "TMDB", StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	s.cache.set(key, body)
+	return body, nil
+}
+
+// addLocale asks TMDB for the request's language. Regional results are
+// opt-in per endpoint since most of TMDB ignores the region parameter.
+func addLocale(ctx context.Context, params url.Values, regional bool) {
+	locale := LocaleFrom(ctx)
+	params.Set("language", locale.Language)
+	if regional && locale.Region != "" {
+		params.Set("region", locale.Region)
+	}
+}
+
+// addMediaLanguages keeps appended videos and images from being limited to
+// the request's language: English and language-neutral ones are included
+// too, since most titles have few localized trailers or posters
+func addMediaLanguages(ctx context.Context, params url.Values) {
+	language, _, _ := strings.Cut(LocaleFrom(ctx).Language, "-")
+	languages := language + ",null"
+	if language != "en" {
+		languages = language + ",en,null"
+	}
+	params.Set("include_video_language", languages)
+	params.Set("include_image_language", languages)
+}
+
+// fetchEnglish repeats a localized request in DefaultLanguage, for filling
+// in fields that have no translation yet. Responses are cached per
+// language, so this usually costs no upstream call.
+func (s *TMDBService) fetchEnglish(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	english := url.Values{}
+	for k, v := range params {
+		english[k] = v
+	}
+	english.Set("language", DefaultLanguage)
+	return s.fetch(ctx, endpoint, english)
+}
+
+// fillUntranslated copies English titles and overviews into list results
+// TMDB has no translation for. It is best-effort: if the English request
+// fails, the results are returned as translated.
+func (s *TMDBService) fillUntranslated(ctx context.Context, endpoint string, params url.Values, results []models.ResultItem) {
+	if LocaleFrom(ctx).English() || !hasUntranslated(results) {
+		return
+	}
+	body, err := s.fetchEnglish(ctx, endpoint, params)
+	if err != nil {
+		return
+	}
+	var english models.SearchResult
+	if err := json.Unmarshal(body, &english); err != nil {
+		return
+	}
+	byID := make(map[int]models.ResultItem, len(english.Results))
+	for _, item := range english.Results {
+		byID[item.ID] = item
+	}
+	for i := range results {
+		if fallback, ok := byID[results[i].ID]; ok {
+			fillEmpty(&results[i].Title, fallback.Title)
+			fillEmpty(&results[i].Overview, fallback.Overview)
+		}
+	}
+}
+
+func hasUntranslated(results []models.ResultItem) bool {
+	for _, item := range results {
+		// People have no overview to translate
+		if item.Title == "" || (item.Overview == "" && item.MediaType != models.MediaTypePerson) {
+			return true
+		}
+	}
+	return false
+}
+
+// setMediaType tags results of single-type endpoints, which TMDB leaves
+// untagged
+func setMediaType(results []models.ResultItem, mediaType string) {
+	for i := range results {
+		if results[i].MediaType == "" {
+			results[i].MediaType = mediaType
+		}
+	}
+}
+
+func cacheParams(params url.Values) string {
+	keyed := url.Values{}
+	for k, v := range params {
+		if k != "api_key" {
+			keyed[k] = v
+		}
+	}
+	return keyed.Encode()
+}
+
+// SearchOptions are the search filters TMDB applies itself
+type SearchOptions struct {
+	// Year restricts movies to their release year and shows to the year
+	// they first aired; zero means any year
+	Year         int
+	IncludeAdult bool
+}
+
+// SearchMovies searches for movies using TMDB API
+func (s *TMDBService) SearchMovies(ctx context.Context, query string, page int, opts SearchOptions) (*models.SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/search/movie", s.baseURL)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("query", query)
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", strconv.FormatBool(opts.IncludeAdult))
+	addLocale(ctx, params, true)
+	if opts.Year > 0 {
+		params.Add("primary_release_year", strconv.Itoa(opts.Year))
+	}
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search movies: %w", err)
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, models.MediaTypeMovie)
+	s.fillUntranslated(ctx, endpoint, params, result.Results)
+
+	return &result, nil
+}
+
+// SearchTVShows searches for TV shows using TMDB API
+func (s *TMDBService) SearchTVShows(ctx context.Context, query string, page int, opts SearchOptions) (*models.SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/search/tv", s.baseURL)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("query", query)
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", strconv.FormatBool(opts.IncludeAdult))
+	addLocale(ctx, params, false)
+	if opts.Year > 0 {
+		params.Add("first_air_date_year", strconv.Itoa(opts.Year))
+	}
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search TV shows: %w", err)
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, models.MediaTypeTV)
+	s.fillUntranslated(ctx, endpoint, params, result.Results)
+
+	return &result, nil
+}
+
+// GetMovieProviders fetches streaming providers for a movie
+func (s *TMDBService) GetMovieProviders(ctx context.Context, movieID int) (map[string]interface{}, error) {
+	endpoint := fmt.Sprintf("%s/movie/%d/watch/providers", s.baseURL, movieID)
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie providers: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode providers: %w", err)
+	}
+	return result, nil
+}
+
+// GetMovieDetails gets detailed information about a movie
+func (s *TMDBService) GetMovieDetails(ctx context.Context, movieID int) (*models.Movie, error) {
+	endpoint := fmt.Sprintf("%s/movie/%d", s.baseURL, movieID)
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+	params.Add("append_to_response", "credits,videos,images")
+	addMediaLanguages(ctx, params)
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie details: %w", err)
+	}
+	var movie models.Movie
+	if err := json.Unmarshal(body, &movie); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !LocaleFrom(ctx).English() && (movie.Title == "" || movie.Overview == "" || movie.Tagline == "") {
+		if body, err := s.fetchEnglish(ctx, endpoint, params); err == nil {
+			var english models.Movie
+			if json.Unmarshal(body, &english) == nil {
+				fillEmpty(&movie.Title, english.Title)
+				fillEmpty(&movie.Overview, english.Overview)
+				fillEmpty(&movie.Tagline, english.Tagline)
+			}
+		}
+	}
+	// Fetch providers
+	providers, _ := s.GetMovieProviders(ctx, movieID)
+	if providers != nil {
+		movie.Providers = providers["results"]
+	}
+	movie.Trailer = movie.Videos.Trailer()
+	return &movie, nil
+}
+
+// GetTVDetails gets detailed information about a TV show
+func (s *TMDBService) GetTVDetails(ctx context.Context, tvID int) (*models.TVShow, error) {
+	endpoint := fmt.Sprintf("%s/tv/%d", s.baseURL, tvID)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+	params.Add("append_to_response", "credits,videos,images")
+	addMediaLanguages(ctx, params)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TV show details: %w", err)
+	}
+
+	var tvShow models.TVShow
+	if err := json.Unmarshal(body, &tvShow); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !LocaleFrom(ctx).English() && (tvShow.Name == "" || tvShow.Overview == "") {
+		if body, err := s.fetchEnglish(ctx, endpoint, params); err == nil {
+			var english models.TVShow
+			if json.Unmarshal(body, &english) == nil {
+				fillEmpty(&tvShow.Name, english.Name)
+				fillEmpty(&tvShow.Overview, english.Overview)
+			}
+		}
+	}
+	tvShow.Trailer = tvShow.Videos.Trailer()
+
+	return &tvShow, nil
+}
+
+// GetCollection gets a movie collection and its parts in release order;
+// parts without a release date come last
+func (s *TMDBService) GetCollection(ctx context.Context, collectionID int) (*models.Collection, error) {
+	endpoint := fmt.Sprintf("%s/collection/%d", s.baseURL, collectionID)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	var collection models.Collection
+	if err := json.Unmarshal(body, &collection); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !LocaleFrom(ctx).English() && (collection.Overview == "" || hasUntranslatedPart(collection.Parts)) {
+		if body, err := s.fetchEnglish(ctx, endpoint, params); err == nil {
+			var english models.Collection
+			if json.Unmarshal(body, &english) == nil {
+				fillEmpty(&collection.Name, english.Name)
+				fillEmpty(&collection.Overview, english.Overview)
+				byID := make(map[int]models.CollectionPart, len(english.Parts))
+				for _, part := range english.Parts {
+					byID[part.ID] = part
+				}
+				for i := range collection.Parts {
+					fallback := byID[collection.Parts[i].ID]
+					fillEmpty(&collection.Parts[i].Title, fallback.Title)
+					fillEmpty(&collection.Parts[i].Overview, fallback.Overview)
+				}
+			}
+		}
+	}
+	parts := collection.Parts
+	for i := range parts {
+		parts[i].MediaType = models.MediaTypeMovie
+	}
+	sort.SliceStable(parts, func(i, j int) bool {
+		a, b := parts[i].ReleaseDate, parts[j].ReleaseDate
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		return a < b
+	})
+
+	return &collection, nil
+}
+
+func hasUntranslatedPart(parts []models.CollectionPart) bool {
+	for _, part := range parts {
+		if part.Title == "" || part.Overview == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSeasonDetails gets a TV season and its episodes. Season 0 holds a
+// show's specials.
+func (s *TMDBService) GetSeasonDetails(ctx context.Context, tvID, seasonNumber int) (*models.Season, error) {
+	endpoint := fmt.Sprintf("%s/tv/%d/season/%d", s.baseURL, tvID, seasonNumber)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season details: %w", err)
+	}
+
+	var season models.Season
+	if err := json.Unmarshal(body, &season); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !LocaleFrom(ctx).English() && hasUntranslatedEpisode(season.Episodes) {
+		if body, err := s.fetchEnglish(ctx, endpoint, params); err == nil {
+			var english models.Season
+			if json.Unmarshal(body, &english) == nil {
+				fillEmpty(&season.Overview, english.Overview)
+				byNumber := make(map[int]models.Episode, len(english.Episodes))
+				for _, episode := range english.Episodes {
+					byNumber[episode.EpisodeNumber] = episode
+				}
+				for i := range season.Episodes {
+					fallback := byNumber[season.Episodes[i].EpisodeNumber]
+					fillEmpty(&season.Episodes[i].Name, fallback.Name)
+					fillEmpty(&season.Episodes[i].Overview, fallback.Overview)
+				}
+			}
+		}
+	}
+
+	return &season, nil
+}
+
+func hasUntranslatedEpisode(episodes []models.Episode) bool {
+	for _, episode := range episodes {
+		if episode.Name == "" || episode.Overview == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEpisodeDetails gets one episode with its guest stars, crew and stills
+func (s *TMDBService) GetEpisodeDetails(ctx context.Context, tvID, seasonNumber, episodeNumber int) (*models.Episode, error) {
+	endpoint := fmt.Sprintf("%s/tv/%d/season/%d/episode/%d", s.baseURL, tvID, seasonNumber, episodeNumber)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+	params.Add("append_to_response", "images")
+	addMediaLanguages(ctx, params)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episode details: %w", err)
+	}
+
+	var episode models.Episode
+	if err := json.Unmarshal(body, &episode); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !LocaleFrom(ctx).English() && (episode.Name == "" || episode.Overview == "") {
+		if body, err := s.fetchEnglish(ctx, endpoint, params); err == nil {
+			var english models.Episode
+			if json.Unmarshal(body, &english) == nil {
+				fillEmpty(&episode.Name, english.Name)
+				fillEmpty(&episode.Overview, english.Overview)
+			}
+		}
+	}
+
+	return &episode, nil
+}
+
+// SearchPeople searches for actors, directors and other crew. People have
+// no release year, so only opts.IncludeAdult applies.
+func (s *TMDBService) SearchPeople(ctx context.Context, query string, page int, opts SearchOptions) (*models.SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/search/person", s.baseURL)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("query", query)
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", strconv.FormatBool(opts.IncludeAdult))
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, models.MediaTypePerson)
+
+	return &result, nil
+}
+
+// GetPersonDetails gets a person's biography and profile
+func (s *TMDBService) GetPersonDetails(ctx context.Context, personID int) (*models.Person, error) {
+	endpoint := fmt.Sprintf("%s/person/%d", s.baseURL, personID)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person details: %w", err)
+	}
+
+	var person models.Person
+	if err := json.Unmarshal(body, &person); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	// Most biographies are only written in English
+	if !LocaleFrom(ctx).English() && person.Biography == "" {
+		if body, err := s.fetchEnglish(ctx, endpoint, params); err == nil {
+			var english models.Person
+			if json.Unmarshal(body, &english) == nil {
+				fillEmpty(&person.Biography, english.Biography)
+			}
+		}
+	}
+
+	return &person, nil
+}
+
+// GetPersonCredits gets every movie and TV show a person is credited on
+func (s *TMDBService) GetPersonCredits(ctx context.Context, personID int) (*models.PersonCredits, error) {
+	endpoint := fmt.Sprintf("%s/person/%d/combined_credits", s.baseURL, personID)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get person credits: %w", err)
+	}
+
+	var credits models.PersonCredits
+	if err := json.Unmarshal(body, &credits); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &credits, nil
+}
+
+// GetCompanyDetails gets a production company's profile
+func (s *TMDBService) GetCompanyDetails(ctx context.Context, companyID int) (*models.Company, error) {
+	endpoint := fmt.Sprintf("%s/company/%d", s.baseURL, companyID)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company details: %w", err)
+	}
+
+	var company models.Company
+	if err := json.Unmarshal(body, &company); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &company, nil
+}
+
+// GetCompanyTitles gets a page of a company's movies or TV shows released
+// on or after since (YYYY-MM-DD), announced titles included, soonest first.
+// Undated titles are left out.
+func (s *TMDBService) GetCompanyTitles(ctx context.Context, mediaType string, companyID int, since string, page int) (*models.SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/discover/%s", s.baseURL, mediaType)
+
+	dateField := "first_air_date"
+	if mediaType == models.MediaTypeMovie {
+		dateField = "primary_release_date"
+	}
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("with_companies", strconv.Itoa(companyID))
+	params.Add(dateField+".gte", since)
+	params.Add("sort_by", dateField+".asc")
+	params.Add("include_adult", "false")
+	params.Add("page", strconv.Itoa(page))
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company titles: %w", err)
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, mediaType)
+
+	return &result, nil
+}
+
+// GetTrendingMovies gets trending movies
+func (s *TMDBService) GetTrendingMovies(ctx context.Context, page int) (*models.TrendingResult, error) {
+	endpoint := fmt.Sprintf("%s/trending/movie/week", s.baseURL)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("page", strconv.Itoa(page))
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending movies: %w", err)
+	}
+
+	var result models.TrendingResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, models.MediaTypeMovie)
+	s.fillUntranslated(ctx, endpoint, params, result.Results)
+
+	return &result, nil
+}
+
+// GetTrendingTVShows gets trending TV shows
+func (s *TMDBService) GetTrendingTVShows(ctx context.Context, page int) (*models.TrendingResult, error) {
+	endpoint := fmt.Sprintf("%s/trending/tv/week", s.baseURL)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("page", strconv.Itoa(page))
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending TV shows: %w", err)
+	}
+
+	var result models.TrendingResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, models.MediaTypeTV)
+	s.fillUntranslated(ctx, endpoint, params, result.Results)
+
+	return &result, nil
+}
+
+// GetGenres gets the movie and TV genre lists merged into one catalog,
+// sorted by name. Genres are named in the request's language where TMDB has
+// a translation and in English otherwise.
+func (s *TMDBService) GetGenres(ctx context.Context) ([]models.Genre, error) {
+	var genres []models.Genre
+	byID := make(map[int]int)
+	for _, mediaType := range []string{"movie", "tv"} {
+		list, err := s.getGenreList(ctx, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		for _, genre := range list {
+			if i, ok := byID[genre.ID]; ok {
+				genres[i].MediaTypes = append(genres[i].MediaTypes, mediaType)
+				continue
+			}
+			genre.MediaTypes = []string{mediaType}
+			byID[genre.ID] = len(genres)
+			genres = append(genres, genre)
+		}
+	}
+	sort.Slice(genres, func(i, j int) bool { return genres[i].Name < genres[j].Name })
+	return genres, nil
+}
+
+func (s *TMDBService) getGenreList(ctx context.Context, mediaType string) ([]models.Genre, error) {
+	endpoint := fmt.Sprintf("%s/genre/%s/list", s.baseURL, mediaType)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	addLocale(ctx, params, false)
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s genres: %w", mediaType, err)
+	}
+
+	var response struct {
+		Genres []models.Genre `json:"genres"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if !LocaleFrom(ctx).English() {
+		s.fillGenreNames(ctx, endpoint, params, response.Genres)
+	}
+
+	return response.Genres, nil
+}
+
+func (s *TMDBService) fillGenreNames(ctx context.Context, endpoint string, params url.Values, genres []models.Genre) {
+	missing := false
+	for _, genre := range genres {
+		missing = missing || genre.Name == ""
+	}
+	if !missing {
+		return
+	}
+	body, err := s.fetchEnglish(ctx, endpoint, params)
+	if err != nil {
+		return
+	}
+	var english struct {
+		Genres []models.Genre `json:"genres"`
+	}
+	if json.Unmarshal(body, &english) != nil {
+		return
+	}
+	names := make(map[int]string, len(english.Genres))
+	for _, genre := range english.Genres {
+		names[genre.ID] = genre.Name
+	}
+	for i := range genres {
+		fillEmpty(&genres[i].Name, names[genres[i].ID])
+	}
+}
+
+func fillEmpty(field *string, fallback string) {
+	if *field == "" {
+		*field = fallback
+	}
+}
+
+// DiscoverByGenre lists the most popular movies or TV shows in a genre
+func (s *TMDBService) DiscoverByGenre(ctx context.Context, mediaType string, genreID, page int) (*models.SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/discover/%s", s.baseURL, mediaType)
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("with_genres", strconv.Itoa(genreID))
+	params.Add("sort_by", "popularity.desc")
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", "false")
+	addLocale(ctx, params, mediaType == "movie")
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover titles by genre: %w", err)
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, mediaType)
+	s.fillUntranslated(ctx, endpoint, params, result.Results)
+
+	return &result, nil
+}
+
+// DiscoverOptions are the filters and order TMDB's discover endpoints
+// apply across the whole catalogue
+type DiscoverOptions struct {
+	SearchOptions
+	// Genres matches titles in any of them
+	Genres    []int
+	MinRating float64
+	// Language is the ISO 639-1 original language
+	Language string
+	// Sort is popularity, rating, newest, oldest or title; anything else
+	// sorts by popularity
+	Sort string
+}
+
+// Discover lists the movies or TV shows that match the options
+func (s *TMDBService) Discover(ctx context.Context, mediaType string, page int, opts DiscoverOptions) (*models.SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/discover/%s", s.baseURL, mediaType)
+	dateField, titleField, yearField := "primary_release_date", "title", "primary_release_year"
+	if mediaType == models.MediaTypeTV {
+		dateField, titleField, yearField = "first_air_date", "name", "first_air_date_year"
+	}
+
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+	params.Add("page", strconv.Itoa(page))
+	params.Add("include_adult", strconv.FormatBool(opts.IncludeAdult))
+	addLocale(ctx, params, mediaType == models.MediaTypeMovie)
+	if opts.Year > 0 {
+		params.Add(yearField, strconv.Itoa(opts.Year))
+	}
+	if len(opts.Genres) > 0 {
+		ids := make([]string, len(opts.Genres))
+		for i, id := range opts.Genres {
+			ids[i] = strconv.Itoa(id)
+		}
+		params.Add("with_genres", strings.Join(ids, "|"))
+	}
+	if opts.MinRating > 0 {
+		params.Add("vote_average.gte", strconv.FormatFloat(opts.MinRating, 'f', -1, 64))
+	}
+	if opts.Language != "" {
+		params.Add("with_original_language", opts.Language)
+	}
+	switch opts.Sort {
+	case "rating":
+		// A handful of votes would otherwise put obscure titles first
+		params.Add("sort_by", "vote_average.desc")
+		params.Add("vote_count.gte", "100")
+	case "newest":
+		// Announced titles have far-off dates and nothing else to show yet
+		params.Add("sort_by", dateField+".desc")
+		params.Add(dateField+".lte", time.Now().Format("2006-01-02"))
+	case "oldest":
+		params.Add("sort_by", dateField+".asc")
+	case "title":
+		params.Add("sort_by", titleField+".asc")
+	default:
+		params.Add("sort_by", "popularity.desc")
+	}
+
+	body, err := s.fetch(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover titles: %w", err)
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	setMediaType(result.Results, mediaType)
+	s.fillUntranslated(ctx, endpoint, params, result.Results)
+
+	return &result, nil
+}
+
+// Configured reports whether a TMDB API key is set
+func (s *TMDBService) Configured() bool {
+	return s.apiKey != ""
+}
+
+// Ping checks that TMDB is reachable and accepts the API key. It bypasses
+// the response cache.
+func (s *TMDBService) Ping(ctx context.Context) error {
+	params := url.Values{}
+	params.Add("api_key", s.apiKey)
+
+	resp, err := s.client.get(ctx, fmt.Sprintf("%s/configuration", s.baseURL), params)
+	if err != nil {
+		return fmt.Errorf("failed to reach TMDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &UpstreamError{Service: "TMDB", StatusCode: resp.StatusCode}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"binge-base/config"
+	"binge-base/models"
+)
+
+func TestDiscoverPassesFilters(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/discover/tv" {
+			t.Errorf("requested %s", r.URL.Path)
+		}
+		got = r.URL.Query()
+		json.NewEncoder(w).Encode(models.SearchResult{Page: 1, Results: []models.ResultItem{{ID: 1, Title: "Show"}}})
+	}))
+	defer server.Close()
+
+	tmdb := NewTMDBService(&config.Config{TMDBRateLimit: 1000})
+	tmdb.baseURL = server.URL
+	result, err := tmdb.Discover(context.Background(), models.MediaTypeTV, 2, DiscoverOptions{
+		SearchOptions: SearchOptions{Year: 2019},
+		Genres:        []int{18, 80},
+		MinRating:     7.5,
+		Language:      "ko",
+		Sort:          "oldest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Results) != 1 || result.Results[0].MediaType != models.MediaTypeTV {
+		t.Errorf("results %+v", result.Results)
+	}
+	for param, want := range map[string]string{
+		"page":                   "2",
+		"first_air_date_year":    "2019",
+		"with_genres":            "18|80",
+		"vote_average.gte":       "7.5",
+		"with_original_language": "ko",
+		"sort_by":                "first_air_date.asc",
+		"include_adult":          "false",
+	} {
+		if got.Get(param) != want {
+			t.Errorf("%s = %q, want %q", param, got.Get(param), want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"binge-base/logging"
+	"binge-base/metrics"
+)
+
+var (
+	upstreamRequests = metrics.NewCounterVec("bingebase_upstream_requests_total",
+		"Requests made to third-party APIs by service, endpoint and status code.", "service", "endpoint", "status")
+	upstreamDuration = metrics.NewHistogramVec("bingebase_upstream_request_duration_seconds",
+		"Latency of third-party API requests.", metrics.DefaultBuckets, "service", "endpoint")
+	upstreamRateLimitWait = metrics.NewHistogramVec("bingebase_upstream_rate_limit_wait_seconds",
+		"Time spent waiting on the outbound rate limiter before calling a third-party API.", metrics.DefaultBuckets, "service")
+)
+
+var numericSegment = regexp.MustCompile(`/\d+`)
+
+// ErrNotFound matches errors for resources the upstream API doesn't have
+var ErrNotFound = errors.New("resource not found")
+
+// UpstreamError reports an unexpected status code from a third-party API
+type UpstreamError struct {
+	Service    string
+	StatusCode int
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s API error: %d", e.Service, e.StatusCode)
+}
+
+// Is makes a 404 match ErrNotFound
+func (e *UpstreamError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// upstreamClient performs rate-limited, instrumented GETs against one
+// third-party API
+type upstreamClient struct {
+	service    string
+	httpClient *http.Client
+	limiter    *rateLimiter
+}
+
+func newUpstreamClient(service string, limiter *rateLimiter) *upstreamClient {
+	return &upstreamClient{
+		service: service,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		limiter: limiter,
+	}
+}
+
+// get issues a GET, forwarding the request ID and logging the call's
+// latency against it. The query string is left out of logs and errors
+// since it carries the API key.
+func (c *upstreamClient) get(ctx context.Context, endpoint string, params url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	path := upstreamPath(req.URL)
+	label := numericSegment.ReplaceAllString(path, "/{id}")
+
+	waited, err := c.limiter.wait(ctx)
+	if c.limiter != nil {
+		upstreamRateLimitWait.Observe(waited.Seconds(), c.service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	latency := time.Since(start)
+
+	// Transport errors embed the full URL; drop the query so the API key
+	// doesn't end up in logs or error responses
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = endpoint
+	}
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	upstreamRequests.Inc(c.service, label, status)
+	upstreamDuration.Observe(latency.Seconds(), c.service, label)
+
+	attrs := []slog.Attr{
+		slog.String("service", c.service),
+		slog.String("path", path),
+		slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+	}
+	if waited > 0 {
+		attrs = append(attrs, slog.Float64("rate_limit_wait_ms", float64(waited.Microseconds())/1000))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if resp.StatusCode >= 500 {
+			level = slog.LevelWarn
+		}
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, level, "upstream_request", attrs...)
+
+	return resp, err
+}
+
+func upstreamPath(u *url.URL) string {
+	if path := strings.TrimPrefix(u.Path, "/3"); path != "" {
+		return path
+	}
+	return "/"
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"binge-base/config"
+	"binge-base/database"
+	"binge-base/models"
+)
+
+// Webhook event names
+const (
+	EventWatchlistAdded     = "watchlist.added"
+	EventWatchlistRemoved   = "watchlist.removed"
+	EventWatchlistWatched   = "watchlist.watched"
+	EventWatchlistUnwatched = "watchlist.unwatched"
+	EventReleaseUpcoming    = "release.upcoming"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{
+	EventWatchlistAdded,
+	EventWatchlistRemoved,
+	EventWatchlistWatched,
+	EventWatchlistUnwatched,
+	EventReleaseUpcoming,
+}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body
+const SignatureHeader = "X-BingeBase-Signature"
+
+// ErrPrivateWebhookTarget is returned for webhook URLs that point at
+// loopback, link-local, private or unspecified addresses
+var ErrPrivateWebhookTarget = errors.New("webhook URL must resolve to a public address")
+
+// ErrWebhookQueueFull is returned by Dispatch when a delivery was dropped
+var ErrWebhookQueueFull = errors.New("webhook queue full")
+
+type webhookJob struct {
+	webhook models.Webhook
+	event   models.WebhookEvent
+	body    []byte
+	attempt int
+}
+
+type WebhookService struct {
+	db           *database.Database
+	httpClient   *http.Client
+	allowPrivate bool
+	maxAttempts  int
+	baseBackoff  time.Duration
+	queue        chan webhookJob
+	quit         chan struct{}
+	wg           sync.WaitGroup
+	stopOnce     sync.Once
+}
+
+func NewWebhookService(cfg *config.Config, db *database.Database) *WebhookService {
+	// Addresses are checked again when dialing, so a host that resolved to a
+	// public address on registration can't be rebound to an internal one.
+	// Proxies are bypassed because the check must see the real target.
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !cfg.WebhookAllowPrivate {
+		dialer.Control = publicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookService{
+		db: db,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.WebhookTimeout) * time.Second,
+		},
+		allowPrivate: cfg.WebhookAllowPrivate,
+		maxAttempts:  cfg.WebhookMaxAttempts,
+		baseBackoff:  2 * time.Second,
+		queue:        make(chan webhookJob, 256),
+		quit:         make(chan struct{}),
+	}
+}
+
+// Start launches the delivery workers
+func (s *WebhookService) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+}
+
+// Stop signals the workers to finish and waits for in-flight deliveries.
+// Pending retries are abandoned.
+func (s *WebhookService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+	})
+	s.wg.Wait()
+}
+
+// Dispatch queues an event for every webhook of the user subscribed to it.
+// It never blocks the caller; events are dropped if the queue is full, and
+// an error reports that some webhook won't receive the event. Errors are
+// already logged, so callers that can't retry may ignore them.
+func (s *WebhookService) Dispatch(userID, event string, data interface{}) error {
+	webhooks, err := s.db.GetWebhooksForEvent(userID, event)
+	if err != nil {
+		slog.Error("failed to load webhooks", "user", userID, "error", err)
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload := models.WebhookEvent{
+		ID:        newEventID(),
+		Event:     event,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to encode webhook event", "event", event, "error", err)
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if !s.enqueue(webhookJob{webhook: webhook, event: payload, body: body, attempt: 1}) {
+			err = ErrWebhookQueueFull
+		}
+	}
+	return err
+}
+
+// enqueue hands a job to the workers without blocking, dropping it if the
+// queue is full or the service has stopped
+func (s *WebhookService) enqueue(job webhookJob) bool {
+	select {
+	case <-s.quit:
+		return false
+	default:
+	}
+	select {
+	case s.queue <- job:
+		return true
+	default:
+		slog.Warn("webhook queue full, dropping event", "event", job.event.Event, "webhook_id", job.webhook.ID, "attempt", job.attempt)
+		return false
+	}
+}
+
+func (s *WebhookService) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case job := <-s.queue:
+			s.deliver(job)
+		}
+	}
+}
+
+// deliver POSTs the job once and writes the attempt to the delivery log. A
+// failed attempt is queued again after an exponential backoff until
+// maxAttempts is reached, so dead endpoints don't hold a worker while they
+// wait.
+func (s *WebhookService) deliver(job webhookJob) {
+	delivery := s.attempt(job)
+	if err := s.db.RecordWebhookDelivery(delivery); err != nil {
+		slog.Error("failed to record webhook delivery", "webhook_id", job.webhook.ID, "error", err)
+	}
+	if delivery.Success || job.attempt >= s.maxAttempts {
+		return
+	}
+
+	backoff := s.baseBackoff << (job.attempt - 1)
+	job.attempt++
+	time.AfterFunc(backoff, func() { s.enqueue(job) })
+}
+
+func (s *WebhookService) attempt(job webhookJob) *models.WebhookDelivery {
+	delivery := &models.WebhookDelivery{
+		WebhookID: job.webhook.ID,
+		EventID:   job.event.ID,
+		Event:     job.event.Event,
+		Attempt:   job.attempt,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, job.webhook.URL, bytes.NewReader(job.body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BingeBase-Webhooks/1.0")
+	req.Header.Set("X-BingeBase-Event", job.event.Event)
+	req.Header.Set("X-BingeBase-Delivery", job.event.ID)
+	req.Header.Set("X-BingeBase-Attempt", strconv.Itoa(job.attempt))
+	req.Header.Set(SignatureHeader, "sha256="+SignPayload(job.webhook.Secret, job.body))
+
+	start := time.Now()
+	resp, err := s.httpClient.Do(req)
+	delivery.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Success {
+		delivery.Error = fmt.Sprintf("endpoint returned %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+// CheckHost resolves a webhook host and fails unless every address it
+// resolves to is public
+func (s *WebhookService) CheckHost(ctx context.Context, host string) error {
+	if s.allowPrivate {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host: %w", err)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return ErrPrivateWebhookTarget
+		}
+	}
+	return nil
+}
+
+// publicOnly is a net.Dialer Control function refusing connections to
+// non-public addresses
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateWebhookTarget, address)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), used for
+// internal networking by some hosting platforms
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// SignPayload returns the hex-encoded HMAC-SHA256 of body using secret
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateWebhookSecret returns a random secret for signing payloads
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
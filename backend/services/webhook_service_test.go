@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"binge-base/config"
+	"binge-base/database"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.public {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestDeliveryRefusesPrivateAddresses(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	s := NewWebhookService(&config.Config{WebhookTimeout: 5, WebhookMaxAttempts: 1}, nil)
+	_, err := s.httpClient.Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrPrivateWebhookTarget) || reached {
+		t.Fatalf("expected ErrPrivateWebhookTarget before reaching the server, got %v", err)
+	}
+
+	s = NewWebhookService(&config.Config{WebhookTimeout: 5, WebhookMaxAttempts: 1, WebhookAllowPrivate: true}, nil)
+	resp, err := s.httpClient.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("expected private networks to be allowed, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestFailedDeliveriesAreRetriedWithoutBlockingWorkers(t *testing.T) {
+	db, err := database.NewDatabase(t.TempDir() + "/webhooks.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mu sync.Mutex
+	hits := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/dead" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	dead, err := db.CreateWebhook("u", server.URL+"/dead", "secret", []string{EventWatchlistAdded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateWebhook("u", server.URL+"/live", "secret", []string{EventWatchlistAdded}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewWebhookService(&config.Config{WebhookTimeout: 5, WebhookMaxAttempts: 3, WebhookAllowPrivate: true}, db)
+	s.baseBackoff = 50 * time.Millisecond
+	s.Start(1)
+	defer s.Stop()
+
+	// With a single worker, the live endpoint must be served while the
+	// dead one waits for its retries
+	s.Dispatch("u", EventWatchlistAdded, nil)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		live, failed := hits["/live"], hits["/dead"]
+		mu.Unlock()
+		if live == 1 && failed == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("live=%d dead=%d before the first retry", live, failed)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		deliveries, err := db.GetWebhookDeliveries("u", dead.ID, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 recorded attempts, got %d", len(deliveries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(250 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["/dead"] != 3 || hits["/live"] != 1 {
+		t.Fatalf("expected 3 attempts on the dead endpoint and 1 on the live one, got %v", hits)
+	}
+}
+
+func TestDispatchReportsDroppedEvents(t *testing.T) {
+	db, err := database.NewDatabase(t.TempDir() + "/webhooks.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := NewWebhookService(&config.Config{WebhookTimeout: 5, WebhookMaxAttempts: 1}, db)
+	if err := s.Dispatch("u", EventReleaseUpcoming, nil); err != nil {
+		t.Fatalf("dispatch without webhooks: %v", err)
+	}
+	if _, err := db.CreateWebhook("u", "https://example.com/hook", "secret", []string{EventReleaseUpcoming}); err != nil {
+		t.Fatal(err)
+	}
+	s.queue = make(chan webhookJob) // no workers and no buffer: every job is dropped
+	if err := s.Dispatch("u", EventReleaseUpcoming, nil); !errors.Is(err, ErrWebhookQueueFull) {
+		t.Fatalf("expected ErrWebhookQueueFull, got %v", err)
+	}
+
+	// A dropped notice is forgotten so the next scan sends it again
+	if first, err := db.MarkReleaseNotified("u", 1, "movie", "2030-01-01"); err != nil || !first {
+		t.Fatalf("first mark: %v %v", first, err)
+	}
+	if err := db.UnmarkReleaseNotified("u", 1, "movie", "2030-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if first, err := db.MarkReleaseNotified("u", 1, "movie", "2030-01-01"); err != nil || !first {
+		t.Fatalf("mark after reset: %v %v", first, err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+
+	"binge-base/logging"
+	"binge-base/models"
+)
+
+// User settings handlers
+func (s *Server) getSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	settings, err := s.db.GetUserSettings(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get settings")
+		return
+	}
+	if settings.UpdatedAt != nil {
+		setLastModified(w, *settings.UpdatedAt)
+	}
+	s.sendData(w, settings)
+}
+
+type updateSettingsRequest struct {
+	UserID       string `json:"user_id"`
+	IncludeAdult bool   `json:"include_adult"`
+}
+
+func (s *Server) updateSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	var request updateSettingsRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	settings, err := s.db.SaveUserSettings(models.UserSettings{UserID: request.UserID, IncludeAdult: request.IncludeAdult})
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to save settings")
+		return
+	}
+	s.sendData(w, settings)
+}
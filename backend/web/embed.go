@@ -0,0 +1,22 @@
+//go:build embedfrontend
+
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist is filled by `npm run build:embed` in frontend/
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Assets returns the embedded frontend build
+func Assets() (fs.FS, bool) {
+	assets, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, false
+	}
+	return assets, true
+}
@@ -0,0 +1,11 @@
+//go:build !embedfrontend
+
+package web
+
+import "io/fs"
+
+// Assets reports that this binary was built without the frontend. Build
+// with -tags embedfrontend to include it.
+func Assets() (fs.FS, bool) {
+	return nil, false
+}
@@ -0,0 +1,150 @@
+// Package web serves the production build of the frontend from the binary,
+// so the API and the app it talks to share one origin.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Vite writes content-hashed file names under assets/, so their contents
+// never change and browsers may keep them indefinitely
+const (
+	hashedDir        = "assets/"
+	immutableCaching = "public, max-age=31536000, immutable"
+	defaultCaching   = "public, max-age=3600"
+	// index.html names the current hashed assets and must be revalidated
+	indexCaching = "no-cache"
+)
+
+// precompressed lists the encodings looked for next to each file, in order
+// of preference, as written by frontend/scripts/compress.js
+var precompressed = []struct{ encoding, suffix string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// WithFrontend serves the app for GET and HEAD requests outside the API
+// prefixes and hands everything else to api. Paths that aren't files get
+// index.html so client-side routes survive a reload, except paths with a
+// file extension, which are missing assets and get a 404.
+func WithFrontend(api http.Handler, assets fs.FS, apiPrefixes ...string) http.Handler {
+	files := &fileServer{assets: assets, etags: make(map[string]string)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			api.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range apiPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				api.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" || !files.exists(name) {
+			if path.Ext(name) != "" {
+				http.NotFound(w, r)
+				return
+			}
+			name = "index.html"
+		}
+		files.serve(w, r, name)
+	})
+}
+
+type fileServer struct {
+	assets fs.FS
+
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+func (f *fileServer) exists(name string) bool {
+	info, err := fs.Stat(f.assets, name)
+	return err == nil && !info.IsDir()
+}
+
+func (f *fileServer) serve(w http.ResponseWriter, r *http.Request, name string) {
+	h := w.Header()
+	h.Add("Vary", "Accept-Encoding")
+	switch {
+	case name == "index.html":
+		h.Set("Cache-Control", indexCaching)
+	case strings.HasPrefix(name, hashedDir):
+		h.Set("Cache-Control", immutableCaching)
+	default:
+		h.Set("Cache-Control", defaultCaching)
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h.Set("Content-Type", contentType)
+
+	served := name
+	for _, p := range precompressed {
+		if acceptsEncoding(r.Header.Get("Accept-Encoding"), p.encoding) && f.exists(name+p.suffix) {
+			served = name + p.suffix
+			h.Set("Content-Encoding", p.encoding)
+			break
+		}
+	}
+
+	body, err := fs.ReadFile(f.assets, served)
+	if err != nil {
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+	h.Set("ETag", f.etag(served, body))
+	// Embedded files have no modification time, so only the ETag is used
+	// for conditional requests
+	http.ServeContent(w, r, served, time.Time{}, bytes.NewReader(body))
+}
+
+// etag hashes a file once; embedded contents can't change while running
+func (f *fileServer) etag(name string, body []byte) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if tag, ok := f.etags[name]; ok {
+		return tag
+	}
+	sum := sha256.Sum256(body)
+	tag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	f.etags[name] = tag
+	return tag
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows
+// encoding, honouring q=0 and the "*" wildcard
+func acceptsEncoding(header, encoding string) bool {
+	accepted := false
+	for _, entry := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != encoding && coding != "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding == encoding {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"binge-base/logging"
+	"binge-base/models"
+	"binge-base/services"
+)
+
+// Webhook handlers
+func (s *Server) getWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	webhooks, err := s.db.GetWebhooks(userID)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get webhooks")
+		return
+	}
+	// Secrets are only revealed once, on creation
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	s.sendData(w, webhooks)
+}
+
+type createWebhookRequest struct {
+	UserID string   `json:"user_id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+func (s *Server) createWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var request createWebhookRequest
+	if !s.decodeJSON(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		request.UserID = "default_user"
+	}
+	logging.SetUser(r.Context(), request.UserID)
+	u, err := url.Parse(request.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Webhook URL must be an absolute http(s) URL")
+		return
+	}
+	if err := s.webhookService.CheckHost(r.Context(), u.Hostname()); err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Webhook URL must resolve to a public address")
+		return
+	}
+	if len(request.Events) == 0 {
+		request.Events = services.WebhookEvents
+	}
+	for _, event := range request.Events {
+		if !isWebhookEvent(event) {
+			s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Unknown webhook event: "+event)
+			return
+		}
+	}
+	if request.Secret == "" {
+		secret, err := services.GenerateWebhookSecret()
+		if err != nil {
+			s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to create webhook")
+			return
+		}
+		request.Secret = secret
+	}
+
+	webhook, err := s.db.CreateWebhook(request.UserID, request.URL, request.Secret, request.Events)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to create webhook")
+		return
+	}
+	s.sendJSON(w, http.StatusCreated, models.APIResponse{Success: true, Data: webhook})
+}
+
+func (s *Server) deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	webhookID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid webhook ID")
+		return
+	}
+	if err := s.db.DeleteWebhook(userID, webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.sendError(w, http.StatusNotFound, models.ErrCodeNotFound, "Webhook not found")
+			return
+		}
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to delete webhook")
+		return
+	}
+	s.sendMessage(w, "Webhook deleted")
+}
+
+// Webhook delivery log handler
+func (s *Server) webhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = "default_user"
+	}
+	webhookID, err := strconv.Atoi(r.URL.Query().Get("webhook_id"))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, models.ErrCodeInvalidID, "Invalid webhook ID")
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	if _, err := s.db.GetWebhook(userID, webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.sendError(w, http.StatusNotFound, models.ErrCodeNotFound, "Webhook not found")
+			return
+		}
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get webhook")
+		return
+	}
+
+	deliveries, err := s.db.GetWebhookDeliveries(userID, webhookID, limit)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, models.ErrCodeInternal, "Failed to get webhook deliveries")
+		return
+	}
+	s.sendData(w, deliveries)
+}
+
+func isWebhookEvent(event string) bool {
+	if event == "*" {
+		return true
+	}
+	for _, e := range services.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+// Writes .br and .gz copies of compressible build output next to the
+// originals, for the Go server to send to clients that accept them.
+// Usage: node scripts/compress.js <dir>
+import { readdirSync, readFileSync, statSync, writeFileSync } from 'node:fs'
+import { extname, join } from 'node:path'
+import { brotliCompressSync, constants, gzipSync } from 'node:zlib'
+
+const COMPRESSIBLE = new Set(['.html', '.js', '.css', '.svg', '.json', '.txt', '.map', '.ico'])
+// Below this size the encoding overhead outweighs the savings
+const MIN_SIZE = 1024
+
+function walk(dir) {
+  return readdirSync(dir).flatMap((name) => {
+    const path = join(dir, name)
+    return statSync(path).isDirectory() ? walk(path) : [path]
+  })
+}
+
+const root = process.argv[2] || 'dist'
+let written = 0
+for (const file of walk(root)) {
+  if (!COMPRESSIBLE.has(extname(file))) continue
+  const data = readFileSync(file)
+  if (data.length < MIN_SIZE) continue
+
+  const br = brotliCompressSync(data, { params: { [constants.BROTLI_PARAM_QUALITY]: 11 } })
+  const gz = gzipSync(data, { level: 9 })
+  // Keep a copy only when it is actually smaller
+  if (br.length < data.length) { writeFileSync(file + '.br', br); written++ }
+  if (gz.length < data.length) { writeFileSync(file + '.gz', gz); written++ }
+}
+console.log(`compressed ${written} files in ${root}`)
@@ -6,6 +6,9 @@ import Home from './pages/Home.jsx'
 import Search from './pages/Search.jsx'
 import MovieDetails from './pages/MovieDetails.jsx'
 import TVDetails from './pages/TVDetails.jsx'
+import SeasonDetails from './pages/SeasonDetails.jsx'
+import PersonDetails from './pages/PersonDetails.jsx'
+import CollectionDetails from './pages/CollectionDetails.jsx'
 import Watchlist from './pages/Watchlist.jsx'
 import Trending from './pages/Trending.jsx'
 import './styles/App.css'
@@ -34,6 +37,9 @@ const App = () => {
             <Route path="/search" element={<Search />} />
             <Route path="/movie/:id" element={<MovieDetails />} />
             <Route path="/tv/:id" element={<TVDetails />} />
+            <Route path="/tv/:id/season/:season" element={<SeasonDetails />} />
+            <Route path="/person/:id" element={<PersonDetails />} />
+            <Route path="/collection/:id" element={<CollectionDetails />} />
             <Route path="/watchlist" element={<Watchlist />} />
             <Route path="/trending" element={<Trending />} />
           </Routes>
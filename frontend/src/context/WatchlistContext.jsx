@@ -52,6 +52,21 @@ export const WatchlistProvider = ({ children }) => {
     }
   }
 
+  // Returns the collection with its parts' updated watch state
+  const addCollection = async (collectionId) => {
+    dispatch({ type: 'SET_LOADING', payload: true })
+    try {
+      const added = await watchlistAPI.addCollection(userId, collectionId)
+      // Refresh watchlist
+      const response = await watchlistAPI.getWatchlist(userId)
+      dispatch({ type: 'SET_WATCHLIST', payload: response.data.data || [] })
+      return added.data.data
+    } catch (error) {
+      dispatch({ type: 'SET_ERROR', payload: 'Failed to add collection to watchlist' })
+      return null
+    }
+  }
+
   const removeFromWatchlist = async (contentId, contentType) => {
     dispatch({ type: 'SET_LOADING', payload: true })
     try {
@@ -104,6 +119,7 @@ export const WatchlistProvider = ({ children }) => {
   const value = {
     ...state,
     addToWatchlist,
+    addCollection,
     removeFromWatchlist,
     markAsWatched,
     markAsUnwatched,
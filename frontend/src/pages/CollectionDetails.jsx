@@ -0,0 +1,97 @@
+import React, { useEffect, useState } from 'react'
+import { Link, useParams } from 'react-router-dom'
+import { contentAPI, apiUtils } from '../services/api.js'
+import { useWatchlist } from '../context/WatchlistContext.jsx'
+
+const CollectionDetails = () => {
+  const { id } = useParams()
+  const { addCollection } = useWatchlist()
+  const [collection, setCollection] = useState(null)
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState(null)
+  const [adding, setAdding] = useState(false)
+
+  useEffect(() => {
+    const fetchCollection = async () => {
+      try {
+        setLoading(true)
+        setError(null)
+        const response = await contentAPI.getCollection(id)
+        setCollection(response.data.data)
+      } catch (err) {
+        setError('Failed to load collection.')
+      } finally {
+        setLoading(false)
+      }
+    }
+    fetchCollection()
+  }, [id])
+
+  const addAll = async () => {
+    setAdding(true)
+    const updated = await addCollection(Number(id))
+    if (updated) setCollection(updated)
+    setAdding(false)
+  }
+
+  if (loading) {
+    return (
+      <div className="page">
+        <div className="text-center">
+          <div className="loading-spinner"></div>
+          <p>Loading collection...</p>
+        </div>
+      </div>
+    )
+  }
+  if (error) {
+    return (
+      <div className="page">
+        <div className="error">{error}</div>
+      </div>
+    )
+  }
+  if (!collection) return null
+
+  const parts = collection.parts || []
+  const allAdded = parts.length > 0 && parts.every(part => part.on_watchlist)
+
+  return (
+    <div className="page">
+      <div className="page-header">
+        <h1 className="page-title">{collection.name}</h1>
+        <p className="page-subtitle">{parts.filter(part => part.watched).length} of {parts.length} watched</p>
+      </div>
+      <div className="details-grid">
+        <img
+          src={apiUtils.getPosterURL(collection.poster_path)}
+          alt={collection.name}
+          className="details-poster"
+          onError={e => { e.target.src = '/placeholder-poster.jpg' }}
+        />
+        <div className="details-content">
+          <p>{collection.overview || 'No overview available.'}</p>
+          <div className="watchlist-action">
+            <button onClick={addAll} disabled={adding || allAdded} className="btn btn-primary">
+              {allAdded ? 'All on watchlist' : adding ? 'Adding...' : 'Add all to watchlist'}
+            </button>
+          </div>
+        </div>
+      </div>
+      <div className="filmography">
+        <h2>Movies</h2>
+        <ol>
+          {parts.map(part => (
+            <li key={part.id}>
+              <Link to={`/movie/${part.id}`}>{part.title}</Link>
+              {part.release_date && ` (${part.release_date.slice(0, 4)})`}
+              {part.watched ? ' ✓ Watched' : part.on_watchlist ? ' • On watchlist' : ''}
+            </li>
+          ))}
+        </ol>
+      </div>
+    </div>
+  )
+}
+
+export default CollectionDetails
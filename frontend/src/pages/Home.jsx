@@ -18,8 +18,8 @@ const Home = () => {
           trendingAPI.getTrendingMovies(1),
           trendingAPI.getTrendingTV(1)
         ])
-        setTrendingMovies(moviesResponse.data.results || [])
-        setTrendingTV(tvResponse.data.results || [])
+        setTrendingMovies(moviesResponse.data.data || [])
+        setTrendingTV(tvResponse.data.data || [])
       } catch (err) {
         setError('Failed to load trending content. Please try again later.')
       } finally {
@@ -1,5 +1,5 @@
 import React, { useEffect, useState } from 'react'
-import { useParams } from 'react-router-dom'
+import { Link, useParams } from 'react-router-dom'
 import { contentAPI, apiUtils } from '../services/api.js'
 import { useWatchlist } from '../context/WatchlistContext.jsx'
 
@@ -84,6 +84,9 @@ const MovieDetails = () => {
             <div><strong>Status:</strong> {movie.status}</div>
             <div><strong>Budget:</strong> ${movie.budget?.toLocaleString()}</div>
             <div><strong>Revenue:</strong> ${movie.revenue?.toLocaleString()}</div>
+            {movie.belongs_to_collection && (
+              <div><strong>Part of:</strong> <Link to={`/collection/${movie.belongs_to_collection.id}`}>{movie.belongs_to_collection.name}</Link></div>
+            )}
           </div>
           {/* Add more details as needed, e.g., cast, ratings from OMDB, etc. */}
           <div className="watchlist-action">
@@ -0,0 +1,117 @@
+import React, { useEffect, useState } from 'react'
+import { Link, useParams } from 'react-router-dom'
+import { contentAPI, followAPI, apiUtils } from '../services/api.js'
+
+const PersonDetails = () => {
+  const { id } = useParams()
+  const [person, setPerson] = useState(null)
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState(null)
+  const [following, setFollowing] = useState(false)
+
+  useEffect(() => {
+    followAPI.getFollows()
+      .then(response => setFollowing((response.data.data || []).some(f => f.target_type === 'person' && f.target_id === Number(id))))
+      .catch(() => setFollowing(false))
+  }, [id])
+
+  const toggleFollow = async () => {
+    try {
+      if (following) {
+        await followAPI.unfollow('default_user', 'person', Number(id))
+      } else {
+        await followAPI.follow('default_user', 'person', Number(id))
+      }
+      setFollowing(!following)
+    } catch (err) {
+      console.error('Follow error:', err)
+    }
+  }
+
+  useEffect(() => {
+    const fetchPerson = async () => {
+      try {
+        setLoading(true)
+        setError(null)
+        const response = await contentAPI.getPersonDetails(id)
+        setPerson(response.data.data)
+      } catch (err) {
+        setError('Failed to load person details.')
+      } finally {
+        setLoading(false)
+      }
+    }
+    fetchPerson()
+  }, [id])
+
+  if (loading) {
+    return (
+      <div className="page">
+        <div className="text-center">
+          <div className="loading-spinner"></div>
+          <p>Loading person details...</p>
+        </div>
+      </div>
+    )
+  }
+  if (error) {
+    return (
+      <div className="page">
+        <div className="error">{error}</div>
+      </div>
+    )
+  }
+  if (!person) return null
+
+  const CreditList = ({ title, credits, role }) => (
+    credits?.length > 0 && (
+      <div className="filmography">
+        <h2>{title}</h2>
+        <ul>
+          {credits.map(credit => (
+            <li key={`${credit.credit_id}`}>
+              <Link to={`/${credit.media_type}/${credit.id}`}>{credit.title}</Link>
+              {credit.release_date && ` (${credit.release_date.slice(0, 4)})`}
+              {role(credit) && ` — ${role(credit)}`}
+              {credit.watched ? ' ✓ Watched' : credit.on_watchlist ? ' • On watchlist' : ''}
+            </li>
+          ))}
+        </ul>
+      </div>
+    )
+  )
+
+  return (
+    <div className="page">
+      <div className="page-header">
+        <h1 className="page-title">{person.name}</h1>
+        <p className="page-subtitle">{person.known_for_department}</p>
+      </div>
+      <div className="details-grid">
+        <img
+          src={apiUtils.getPosterURL(person.profile_path)}
+          alt={person.name}
+          className="details-poster"
+          onError={e => { e.target.src = '/placeholder-poster.jpg' }}
+        />
+        <div className="details-content">
+          <h2>Biography</h2>
+          <p>{person.biography || 'No biography available.'}</p>
+          <div className="details-meta">
+            {person.birthday && <div><strong>Born:</strong> {apiUtils.formatDate(person.birthday)}{person.place_of_birth && `, ${person.place_of_birth}`}</div>}
+            {person.deathday && <div><strong>Died:</strong> {apiUtils.formatDate(person.deathday)}</div>}
+          </div>
+          <div className="watchlist-action">
+            <button onClick={toggleFollow} className={`btn ${following ? 'btn-secondary' : 'btn-primary'}`}>
+              {following ? 'Unfollow' : 'Follow for new titles'}
+            </button>
+          </div>
+        </div>
+      </div>
+      <CreditList title="Acting" credits={person.filmography?.cast} role={c => c.character} />
+      <CreditList title="Crew" credits={person.filmography?.crew} role={c => c.job} />
+    </div>
+  )
+}
+
+export default PersonDetails
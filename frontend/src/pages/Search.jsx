@@ -7,7 +7,7 @@ const Search = () => {
   const [results, setResults] = useState([])
   const [loading, setLoading] = useState(false)
   const [error, setError] = useState(null)
-  const [searchType, setSearchType] = useState('all') // 'all', 'movies', 'tv'
+  const [searchType, setSearchType] = useState('all') // 'all', 'movies', 'tv', 'people'
   const [page, setPage] = useState(1)
   const [totalPages, setTotalPages] = useState(0)
   const [yearMin, setYearMin] = useState('')
@@ -37,17 +37,20 @@ const Search = () => {
         case 'tv':
           response = await searchAPI.searchTV(searchQuery, pageNum)
           break
+        case 'people':
+          response = await searchAPI.searchPeople(searchQuery, pageNum)
+          break
         default:
           response = await searchAPI.search(searchQuery, pageNum)
       }
 
       if (pageNum === 1) {
-        setResults(response.data.results || [])
+        setResults(response.data.data || [])
       } else {
-        setResults(prev => [...prev, ...(response.data.results || [])])
+        setResults(prev => [...prev, ...(response.data.data || [])])
       }
       
-      setTotalPages(response.data.total_pages || 0)
+      setTotalPages(response.data.pagination?.total_pages || 0)
     } catch (err) {
       console.error('Search error:', err)
       setError('Failed to search. Please try again.')
@@ -61,7 +64,7 @@ const Search = () => {
     setError(null)
     try {
       const response = await searchAPI.search(query)
-      setResults(response.data.results || [])
+      setResults(response.data.data || [])
     } catch (err) {
       setError('Failed to fetch results.')
     } finally {
@@ -94,7 +97,7 @@ const Search = () => {
   const ContentCard = ({ item, type }) => (
     <div className="card">
       <img 
-        src={apiUtils.getPosterURL(item.poster_path)} 
+        src={apiUtils.getPosterURL(type === 'person' ? item.profile_path : item.poster_path)} 
         alt={item.title || item.name}
         className="card-image"
         onError={(e) => {
@@ -106,7 +109,9 @@ const Search = () => {
           {apiUtils.truncateText(item.title || item.name, 30)}
         </h3>
         <p className="card-text">
-          {apiUtils.truncateText(item.overview, 100)}
+          {type === 'person'
+            ? item.known_for?.map(title => title.title).join(', ')
+            : apiUtils.truncateText(item.overview, 100)}
         </p>
         <div className="card-meta">
           <span>⭐ {item.vote_average?.toFixed(1) || 'N/A'}</span>
@@ -165,6 +170,12 @@ const Search = () => {
           >
             TV Shows
           </button>
+          <button
+            className={`btn ${searchType === 'people' ? 'btn-primary' : 'btn-outline'}`}
+            onClick={() => handleSearchTypeChange('people')}
+          >
+            People
+          </button>
         </div>
       </div>
 
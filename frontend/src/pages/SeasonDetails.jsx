@@ -0,0 +1,91 @@
+import React, { useEffect, useState } from 'react'
+import { Link, useParams } from 'react-router-dom'
+import { contentAPI, watchlistAPI, apiUtils } from '../services/api.js'
+
+const SeasonDetails = () => {
+  const { id, season: seasonNumber } = useParams()
+  const [season, setSeason] = useState(null)
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState(null)
+
+  const fetchSeason = async () => {
+    try {
+      setError(null)
+      const response = await contentAPI.getSeasonDetails(id, seasonNumber)
+      setSeason(response.data.data)
+    } catch (err) {
+      setError('Failed to load season details.')
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  useEffect(() => {
+    setLoading(true)
+    fetchSeason()
+    // eslint-disable-next-line
+  }, [id, seasonNumber])
+
+  const toggleWatched = async (episode) => {
+    try {
+      await watchlistAPI.markEpisodeWatched('default_user', Number(id), episode.season_number, episode.episode_number, !episode.watched)
+      fetchSeason()
+    } catch (err) {
+      console.error('Episode update error:', err)
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="page">
+        <div className="text-center">
+          <div className="loading-spinner"></div>
+          <p>Loading season details...</p>
+        </div>
+      </div>
+    )
+  }
+  if (error) {
+    return (
+      <div className="page">
+        <div className="error">{error}</div>
+      </div>
+    )
+  }
+  if (!season) return null
+
+  return (
+    <div className="page">
+      <div className="page-header">
+        <h1 className="page-title">{season.name}</h1>
+        <p className="page-subtitle"><Link to={`/tv/${id}`}>Back to show</Link></p>
+      </div>
+      {season.overview && <p>{season.overview}</p>}
+      <div className="episodes">
+        {season.episodes?.map(episode => (
+          <div key={episode.id} className="card episode-card">
+            <img
+              src={apiUtils.getPosterURL(episode.still_path, 'w300')}
+              alt={episode.name}
+              className="card-image"
+              onError={e => { e.target.src = '/placeholder-poster.jpg' }}
+            />
+            <div className="card-content">
+              <h3 className="card-title">{episode.episode_number}. {episode.name}</h3>
+              <p className="card-text">{apiUtils.truncateText(episode.overview, 160)}</p>
+              <div className="card-meta">
+                <span>{apiUtils.formatDate(episode.air_date)}</span>
+                {episode.runtime > 0 && <span>{episode.runtime} min</span>}
+              </div>
+              <button onClick={() => toggleWatched(episode)} className={`btn btn-sm ${episode.watched ? 'btn-secondary' : 'btn-outline'}`}>
+                {episode.watched ? '✓ Watched' : 'Mark as watched'}
+              </button>
+            </div>
+          </div>
+        ))}
+      </div>
+    </div>
+  )
+}
+
+export default SeasonDetails
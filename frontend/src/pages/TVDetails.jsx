@@ -1,5 +1,5 @@
 import React, { useEffect, useState } from 'react'
-import { useParams } from 'react-router-dom'
+import { Link, useParams } from 'react-router-dom'
 import { contentAPI, apiUtils } from '../services/api.js'
 import { useWatchlist } from '../context/WatchlistContext.jsx'
 
@@ -81,6 +81,27 @@ const TVDetails = () => {
           </div>
         </div>
       </div>
+      {tv.seasons?.length > 0 && (
+        <div className="seasons">
+          <h2>Seasons</h2>
+          <ul>
+            {tv.seasons.map(season => (
+              <li key={season.id}>
+                <Link to={`/tv/${id}/season/${season.season_number}`}>{season.name}</Link>
+                {season.episode_count > 0 && ` (${season.episode_count} episodes)`}
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
+      {tv.trailer ? (
+        <div className="trailer">
+          <h4>Trailer:</h4>
+          <iframe width="560" height="315" src={`https://www.youtube.com/embed/${tv.trailer}`} frameBorder="0" allowFullScreen></iframe>
+        </div>
+      ) : (
+        <div className="trailer"><em>No trailer available.</em></div>
+      )}
     </div>
   )
 }
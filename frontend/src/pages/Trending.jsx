@@ -24,12 +24,12 @@ const Trending = () => {
       switch (activeTab) {
         case 'movies':
           response = await trendingAPI.getTrendingMovies(page)
-          setTrendingMovies(response.data.results || [])
+          setTrendingMovies(response.data.data || [])
           setTrendingTV([])
           break
         case 'tv':
           response = await trendingAPI.getTrendingTV(page)
-          setTrendingTV(response.data.results || [])
+          setTrendingTV(response.data.data || [])
           setTrendingMovies([])
           break
         default:
@@ -37,12 +37,12 @@ const Trending = () => {
             trendingAPI.getTrendingMovies(page),
             trendingAPI.getTrendingTV(page)
           ])
-          setTrendingMovies(moviesResponse.data.results || [])
-          setTrendingTV(tvResponse.data.results || [])
+          setTrendingMovies(moviesResponse.data.data || [])
+          setTrendingTV(tvResponse.data.data || [])
           response = moviesResponse // Use for pagination
       }
 
-      setTotalPages(response.data.total_pages || 0)
+      setTotalPages(response.data.pagination?.total_pages || 0)
     } catch (err) {
       console.error('Error fetching trending content:', err)
       setError('Failed to load trending content. Please try again later.')
@@ -92,7 +92,7 @@ const Watchlist = () => {
         </p>
         <div className="card-meta">
           <span>⭐ {item.details?.vote_average?.toFixed(1) || item.voteAverage?.toFixed(1) || 'N/A'}</span>
-          <span>{apiUtils.formatDate(item.details?.release_date || item.releaseDate)}</span>
+          <span>{item.details?.year || 'TBA'}</span>
         </div>
         <div style={{ display: 'flex', gap: 'var(--spacing-sm)', marginTop: 'var(--spacing-sm)' }}>
           <Link 
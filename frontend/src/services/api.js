@@ -51,7 +51,7 @@ api.interceptors.response.use(
           console.error('Server error')
           break
         default:
-          console.error(`HTTP ${error.response.status}: ${error.response.data.message || 'Unknown error'}`)
+          console.error(`HTTP ${error.response.status}: ${error.response.data.error || 'Unknown error'}`)
       }
     } else if (error.request) {
       // Request was made but no response received
@@ -78,6 +78,10 @@ export const searchAPI = {
   // Search for TV shows only
   searchTV: (query, page = 1) => 
     api.get('/search/tv', { params: { query, page } }),
+  
+  // Search for actors, directors and crew
+  searchPeople: (query, page = 1) => 
+    api.get('/search/people', { params: { query, page } }),
 }
 
 // Content details API
@@ -89,6 +93,18 @@ export const contentAPI = {
   // Get TV show details
   getTVDetails: (id) => 
     api.get(`/tv/${id}`),
+  
+  // Get a TV season with its episodes
+  getSeasonDetails: (id, season) => 
+    api.get(`/tv/${id}/season/${season}`),
+  
+  // Get a person's details and filmography
+  getPersonDetails: (id) => 
+    api.get(`/person/${id}`),
+  
+  // Get a movie collection with its parts in release order
+  getCollection: (id) => 
+    api.get(`/collection/${id}`),
 }
 
 // Trending API
@@ -108,9 +124,9 @@ export const trendingAPI = {
 
 // Watchlist API
 export const watchlistAPI = {
-  // Get user's watchlist
+  // Get user's watchlist with the title fields the cards show
   getWatchlist: (userId = 'default_user') => 
-    api.get('/watchlist', { params: { user_id: userId } }),
+    api.get('/watchlist', { params: { user_id: userId, fields: 'title,year,poster_path,vote_average,overview' } }),
   
   // Add item to watchlist
   addToWatchlist: (userId, contentId, contentType) => 
@@ -123,13 +139,40 @@ export const watchlistAPI = {
   // Mark item as watched/unwatched
   markAsWatched: (userId, contentId, contentType, isWatched) => 
     api.put('/watchlist', { user_id: userId, content_id: contentId, content_type: contentType, is_watched: isWatched }),
+  
+  // Mark a single TV episode as watched/unwatched
+  markEpisodeWatched: (userId, tvId, seasonNumber, episodeNumber, watched) => 
+    api.put('/watchlist/episodes', { user_id: userId, tv_id: tvId, season_number: seasonNumber, episode_number: episodeNumber, watched }),
+  
+  // Add every movie in a collection to the watchlist
+  addCollection: (userId, collectionId) => 
+    api.post('/watchlist/collection', { user_id: userId, collection_id: collectionId }),
+}
+
+// Follows API
+export const followAPI = {
+  // Get the people and companies a user follows
+  getFollows: (userId = 'default_user') => 
+    api.get('/follows', { params: { user_id: userId } }),
+  
+  // Follow a 'person' or 'company'
+  follow: (userId, targetType, targetId) => 
+    api.post('/follows', { user_id: userId, target_type: targetType, target_id: targetId }),
+  
+  // Stop following
+  unfollow: (userId, targetType, targetId) => 
+    api.delete('/follows', { params: { user_id: userId, target_type: targetType, target_id: targetId } }),
+  
+  // Get new titles from followed people and companies
+  getReleases: (userId = 'default_user') => 
+    api.get('/follows/releases', { params: { user_id: userId } }),
 }
 
 // Genres API
 export const genresAPI = {
-  // Get all genres
-  getGenres: () => 
-    api.get('/genres'),
+  // Get all genres, or only those used for 'movie' or 'tv'
+  getGenres: (type) => 
+    api.get('/genres', { params: { type } }),
   
   // Get movies by genre
   getMoviesByGenre: (genreId, page = 1) => 